@@ -0,0 +1,22 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+)
+
+// viewerID returns the caller's user ID when the request carries a valid
+// bearer token. Public endpoints use it to personalise their responses.
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.NullUUID {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: userID, Valid: true}
+}
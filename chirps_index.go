@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/entities"
+	"github.com/markoc1120/go_server/internal/notifications"
+)
+
+// indexChirp rebuilds the lookup tables derived from a chirp's body. It must
+// run in the same transaction that creates or edits the chirp. The users
+// mentioned for the first time are returned so they can be told about it once
+// the transaction commits.
+func (cfg *apiConfig) indexChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) ([]database.Notification, error) {
+	if err := q.DeleteChirpHashtags(ctx, chirp.ID); err != nil {
+		return nil, err
+	}
+	if tags := entities.Hashtags(chirp.Body); len(tags) > 0 {
+		err := q.AddChirpHashtags(ctx, database.AddChirpHashtagsParams{
+			Tags:      tags,
+			ChirpID:   chirp.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg.indexChirpMentions(ctx, q, chirp)
+}
+
+func (cfg *apiConfig) indexChirpMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) ([]database.Notification, error) {
+	userIDs := []uuid.UUID{}
+	if emails := entities.Mentions(chirp.Body); len(emails) > 0 {
+		users, err := q.GetUsersByEmails(ctx, emails)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+	if len(userIDs) > 0 {
+		blocked, err := q.ListBlockedAmong(ctx, database.ListBlockedAmongParams{
+			UserID:  chirp.UserID,
+			UserIds: userIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		userIDs = slices.DeleteFunc(userIDs, func(id uuid.UUID) bool {
+			return slices.Contains(blocked, id)
+		})
+	}
+
+	err := q.DeleteStaleChirpMentions(ctx, database.DeleteStaleChirpMentionsParams{
+		ChirpID: chirp.ID,
+		UserIds: userIDs,
+	})
+	if err != nil || len(userIDs) == 0 {
+		return nil, err
+	}
+
+	added, err := q.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UserIds:   userIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mentioned []database.Notification
+	for _, userID := range added {
+		notification, ok, err := cfg.notifications.Record(ctx, q, notifications.Notification{
+			UserID:  userID,
+			ActorID: uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+			Kind:    notifications.KindMention,
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			mentioned = append(mentioned, notification)
+		}
+	}
+	return mentioned, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+func (cfg *apiConfig) handlerAuditEventsGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, cursor, err := pagination.Parse(query)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	params := database.ListAuditEventsParams{PageSize: limit + 1}
+	params.CursorCreatedAt, params.CursorID = cursor.QueryArgs()
+	for name, dst := range map[string]*sql.NullString{
+		"action":     &params.Action,
+		"ip":         &params.Ip,
+		"request_id": &params.RequestID,
+	} {
+		if value := query.Get(name); value != "" {
+			*dst = sql.NullString{String: value, Valid: true}
+		}
+	}
+	for name, dst := range map[string]*uuid.NullUUID{
+		"actor_id":  &params.ActorID,
+		"target_id": &params.TargetID,
+	} {
+		if value := query.Get(name); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				response.WithError(w, http.StatusBadRequest, "Invalid "+name+" query parameter", err)
+				return
+			}
+			*dst = uuid.NullUUID{UUID: id, Valid: true}
+		}
+	}
+	for name, dst := range map[string]*sql.NullTime{
+		"since": &params.Since,
+		"until": &params.Until,
+	} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				response.WithError(w, http.StatusBadRequest, name+" must be an RFC 3339 timestamp", err)
+				return
+			}
+			*dst = sql.NullTime{Time: t, Valid: true}
+		}
+	}
+
+	events, err := cfg.db.ListAuditEvents(r.Context(), params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve audit events", err)
+		return
+	}
+
+	page := models.AuditEventPage{Events: []models.AuditEvent{}}
+	if len(events) > int(limit) {
+		events = events[:limit]
+		last := events[len(events)-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, event := range events {
+		page.Events = append(page.Events, audit.FromDB(event))
+	}
+	response.WithJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+// handlerBlockCreate blocks the user in the url. Blocking is mutual: neither
+// user sees the other's chirps, and any follow between them is removed.
+func (cfg *apiConfig) handlerBlockCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	blocked, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	if blocked.ID == userID {
+		response.WithError(w, http.StatusBadRequest, "You can't block yourself", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.CreateBlock(r.Context(), database.CreateBlockParams{
+		BlockerID: userID,
+		BlockedID: blocked.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+	err = qtx.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{
+		UserID:  userID,
+		OtherID: blocked.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't remove follows", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBlockDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid userID in the url", err)
+		return
+	}
+
+	_, err = cfg.db.DeleteBlock(r.Context(), database.DeleteBlockParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't unblock user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBlocksGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	blocks, err := cfg.db.ListBlocks(r.Context(), database.ListBlocksParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve blocked users", err)
+		return
+	}
+
+	users := make([]models.Follow, 0, len(blocks))
+	for _, block := range blocks {
+		users = append(users, models.Follow{UserID: block.BlockedID, CreatedAt: block.CreatedAt})
+	}
+	response.WithJSON(w, http.StatusOK, followPage(users, limit))
+}
+
+// handlerMuteCreate mutes the user in the url. Unlike a block this is one-sided
+// and only hides their chirps and notifications from the caller.
+func (cfg *apiConfig) handlerMuteCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	muted, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	if muted.ID == userID {
+		response.WithError(w, http.StatusBadRequest, "You can't mute yourself", nil)
+		return
+	}
+
+	_, err = cfg.db.CreateMute(r.Context(), database.CreateMuteParams{
+		MuterID: userID,
+		MutedID: muted.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't mute user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerMuteDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	mutedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid userID in the url", err)
+		return
+	}
+
+	_, err = cfg.db.DeleteMute(r.Context(), database.DeleteMuteParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't unmute user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerMutesGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	mutes, err := cfg.db.ListMutes(r.Context(), database.ListMutesParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted users", err)
+		return
+	}
+
+	users := make([]models.Follow, 0, len(mutes))
+	for _, mute := range mutes {
+		users = append(users, models.Follow{UserID: mute.MutedID, CreatedAt: mute.CreatedAt})
+	}
+	response.WithJSON(w, http.StatusOK, followPage(users, limit))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/notifications"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+func (cfg *apiConfig) handlerChirpLikesCreate(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, true)
+}
+
+func (cfg *apiConfig) handlerChirpLikesDelete(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, false)
+}
+
+func (cfg *apiConfig) setChirpLike(w http.ResponseWriter, r *http.Request, liked bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	// Likes of a rechirp go to the chirp it shares, which has the content.
+	chirp, ok := cfg.getReferencableChirp(w, r, userID)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	params := database.CreateChirpLikeParams{ChirpID: chirp.ID, UserID: userID}
+	var changed int64
+	if liked {
+		changed, err = qtx.CreateChirpLike(r.Context(), params)
+	} else {
+		changed, err = qtx.DeleteChirpLike(r.Context(), database.DeleteChirpLikeParams(params))
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update chirp like", err)
+		return
+	}
+
+	likeCount := chirp.LikeCount
+	if changed > 0 {
+		if liked {
+			likeCount, err = qtx.IncrementChirpLikeCount(r.Context(), chirp.ID)
+		} else {
+			likeCount, err = qtx.DecrementChirpLikeCount(r.Context(), chirp.ID)
+		}
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't update like count", err)
+			return
+		}
+	}
+
+	var notified []database.Notification
+	if changed > 0 && liked {
+		notification, ok, err := cfg.notifications.Record(r.Context(), qtx, notifications.Notification{
+			UserID:  chirp.UserID,
+			ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+			Kind:    notifications.KindLike,
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't record notification", err)
+			return
+		}
+		if ok {
+			notified = append(notified, notification)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	cfg.notifications.Publish(notified...)
+	response.WithJSON(w, http.StatusOK, models.ChirpLikes{
+		ChirpID:   chirp.ID,
+		LikeCount: likeCount,
+		LikedByMe: liked,
+	})
+}
+
+// markLikedByViewer sets LikedByMe on every chirp the viewer has liked,
+// including the originals embedded in rechirps and quotes.
+func (cfg *apiConfig) markLikedByViewer(ctx context.Context, viewerID uuid.NullUUID, chirps []models.Chirp) error {
+	if !viewerID.Valid || len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+		if chirp.ReferencedChirp != nil {
+			ids = append(ids, chirp.ReferencedChirp.ID)
+		}
+	}
+	liked, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+		UserID:   viewerID.UUID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+	likedSet := make(map[uuid.UUID]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	markLiked(chirps, likedSet)
+	return nil
+}
+
+func markLiked(chirps []models.Chirp, liked map[uuid.UUID]bool) {
+	for i := range chirps {
+		chirps[i].LikedByMe = liked[chirps[i].ID]
+		if chirps[i].ReferencedChirp != nil {
+			chirps[i].ReferencedChirp.LikedByMe = liked[chirps[i].ReferencedChirp.ID]
+		}
+	}
+}
+
+// showOriginalLikes reports the likes of a rechirp's original on the rechirp
+// itself, since liking a rechirp likes the original, see setChirpLike.
+func showOriginalLikes(chirps []models.Chirp) {
+	for i := range chirps {
+		if chirps[i].Kind != chirpKindRechirp || chirps[i].ReferencedChirp == nil {
+			continue
+		}
+		chirps[i].LikeCount = chirps[i].ReferencedChirp.LikeCount
+		chirps[i].LikedByMe = chirps[i].ReferencedChirp.LikedByMe
+	}
+}
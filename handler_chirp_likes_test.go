@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/models"
+)
+
+func TestRechirpLikes(t *testing.T) {
+	original := models.Chirp{ID: uuid.New(), Kind: chirpKindChirp, LikeCount: 3}
+	otherOriginal := models.Chirp{ID: uuid.New(), Kind: chirpKindChirp, LikeCount: 5}
+	embed := func(c models.Chirp) *models.Chirp { return &c }
+
+	chirps := []models.Chirp{
+		original,
+		{ID: uuid.New(), Kind: chirpKindRechirp, ReferencedChirp: embed(original)},
+		{ID: uuid.New(), Kind: chirpKindQuote, LikeCount: 1, ReferencedChirp: embed(otherOriginal)},
+		{ID: uuid.New(), Kind: chirpKindRechirp, ReferencedChirpDeleted: true},
+	}
+	markLiked(chirps, map[uuid.UUID]bool{original.ID: true, chirps[2].ID: true})
+	showOriginalLikes(chirps)
+
+	tests := []struct {
+		name          string
+		got           models.Chirp
+		wantCount     int32
+		wantLiked     bool
+		wantEmbedded  bool
+		wantEmbedLike bool
+	}{
+		{name: "original", got: chirps[0], wantCount: 3, wantLiked: true},
+		{name: "rechirp shows the original's likes", got: chirps[1], wantCount: 3, wantLiked: true, wantEmbedded: true, wantEmbedLike: true},
+		{name: "quote keeps its own likes", got: chirps[2], wantCount: 1, wantLiked: true, wantEmbedded: true, wantEmbedLike: false},
+		{name: "rechirp of a deleted chirp", got: chirps[3], wantCount: 0, wantLiked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.LikeCount != tt.wantCount || tt.got.LikedByMe != tt.wantLiked {
+				t.Errorf("got (%d, %v), want (%d, %v)", tt.got.LikeCount, tt.got.LikedByMe, tt.wantCount, tt.wantLiked)
+			}
+			if (tt.got.ReferencedChirp != nil) != tt.wantEmbedded {
+				t.Fatalf("embedded = %v, want %v", tt.got.ReferencedChirp != nil, tt.wantEmbedded)
+			}
+			if tt.wantEmbedded && tt.got.ReferencedChirp.LikedByMe != tt.wantEmbedLike {
+				t.Errorf("embedded liked_by_me = %v, want %v", tt.got.ReferencedChirp.LikedByMe, tt.wantEmbedLike)
+			}
+		})
+	}
+}
@@ -1,15 +1,17 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"slices"
-	"strings"
 
+	"github.com/google/uuid"
 	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/database"
 	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/notifications"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
@@ -33,50 +35,95 @@ func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	cleanedBody, err := validateChirp(params.Body)
+	cleanedBody, err := cfg.validateChirp(params.Body)
 	if err != nil {
 		response.WithError(w, http.StatusBadRequest, err.Error(), err)
 		return
 	}
-	chirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleanedBody,
-		UserID: userID,
+
+	var parent database.Chirp
+	var inReplyTo uuid.NullUUID
+	if params.InReplyTo != nil {
+		viewerID := uuid.NullUUID{UUID: userID, Valid: true}
+		parent, err = cfg.visibleChirp(r.Context(), viewerID, *params.InReplyTo)
+		// Replies to a rechirp belong to the conversation of the original.
+		if err == nil && parent.Kind == chirpKindRechirp {
+			if !parent.ReferencedChirpID.Valid {
+				err = sql.ErrNoRows
+			} else {
+				parent, err = cfg.visibleChirp(r.Context(), viewerID, parent.ReferencedChirpID.UUID)
+			}
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				response.WithError(w, http.StatusBadRequest, "in_reply_to chirp not found", nil)
+				return
+			}
+			response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve the single chirp instance from db", err)
+			return
+		}
+		inReplyTo = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		Body:      cleanedBody,
+		UserID:    userID,
+		InReplyTo: inReplyTo,
 	})
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
-	response.WithJSON(w, http.StatusCreated, models.Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	mentioned, err := cfg.indexChirp(r.Context(), qtx, chirp)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't index chirp", err)
+		return
+	}
+	var replied []database.Notification
+	if inReplyTo.Valid && !slices.ContainsFunc(mentioned, func(n database.Notification) bool { return n.UserID == parent.UserID }) {
+		notification, ok, err := cfg.notifications.Record(r.Context(), qtx, notifications.Notification{
+			UserID:  parent.UserID,
+			ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+			Kind:    notifications.KindReply,
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't record notification", err)
+			return
+		}
+		if ok {
+			replied = append(replied, notification)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	payload := chirpFromDB(chirp)
+	cfg.publishChirp(r.Context(), payload)
+	cfg.publishMentions(payload, mentioned)
+	cfg.notifications.Publish(replied...)
+	response.WithJSON(w, http.StatusCreated, payload)
 }
 
-func validateChirp(chirp string) (string, error) {
+func (cfg *apiConfig) validateChirp(chirp string) (string, error) {
 	const maxChirpLength = 140
 	if len(chirp) > maxChirpLength {
 		return "", errors.New("Chirp is too long")
 	}
-	return cleanBody(chirp), nil
-}
-
-func getProfaneWords() []string {
-	return []string{"kerfuffle", "sharbert", "fornax"}
-}
-
-func cleanBody(body string) string {
-	profaneWords := getProfaneWords()
-	var result strings.Builder
-	for w := range strings.SplitSeq(body, " ") {
-		if slices.Contains(profaneWords, strings.ToLower(w)) {
-			result.WriteString("**** ")
-			continue
-		}
-		result.WriteString(w + " ")
+	// The replacement may be longer than the words it replaces.
+	cleaned := cfg.profanity.Clean(chirp)
+	if len(cleaned) > maxChirpLength {
+		return "", errors.New("Chirp is too long once profanity is replaced")
 	}
-
-	return strings.TrimSpace(result.String())
+	return cleaned, nil
 }
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
@@ -44,10 +46,33 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), chirpID)
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if err := deleteChirp(r.Context(), qtx, chirpID); err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't delete the chirp instance from db", err)
 		return
 	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// deleteChirp removes a chirp using q. Rechirps carry no content of their own
+// so they go away with the original, quotes are kept and render the original
+// as deleted.
+func deleteChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID) error {
+	err := q.DeleteRechirpsOf(ctx, uuid.NullUUID{UUID: chirpID, Valid: true})
+	if err != nil {
+		return err
+	}
+	return q.DeleteChirp(ctx, chirpID)
+}
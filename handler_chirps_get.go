@@ -3,59 +3,98 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
-	"sort"
 
 	"github.com/google/uuid"
 	"github.com/markoc1120/go_server/internal/database"
 	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
-func getChirpInstances(ctx context.Context, userID *uuid.UUID, db *database.Queries) ([]database.Chirp, error) {
-	if userID != nil {
-		return db.GetChirpsByUserID(ctx, *userID)
-	}
-	return db.GetChirps(ctx)
+var (
+	errInvalidSort     = errors.New("sort must be either asc or desc")
+	errInvalidAuthorID = errors.New("Invalid author_id query parameter")
+)
+
+type chirpsQuery struct {
+	authorID uuid.NullUUID
+	viewerID uuid.NullUUID
+	cursor   *pagination.Cursor
+	sortType string
+	limit    int32
 }
 
-func getSortedChirps(chirps []database.Chirp, sortType string) []database.Chirp {
-	if sortType == "desc" {
-		sort.Slice(chirps, func(i, j int) bool { return chirps[i].CreatedAt.After(chirps[j].CreatedAt) })
+func getChirpInstances(ctx context.Context, db *database.Queries, q chirpsQuery) ([]database.Chirp, error) {
+	cursorCreatedAt, cursorID := q.cursor.QueryArgs()
+	// Fetch one extra row to find out whether there is a next page.
+	if q.sortType == "desc" {
+		return db.ListChirpsDesc(ctx, database.ListChirpsDescParams{
+			AuthorID:        q.authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			ViewerID:        q.viewerID,
+			PageSize:        q.limit + 1,
+		})
 	}
-	return chirps
+	return db.ListChirpsAsc(ctx, database.ListChirpsAscParams{
+		AuthorID:        q.authorID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		ViewerID:        q.viewerID,
+		PageSize:        q.limit + 1,
+	})
 }
 
-func (cfg *apiConfig) handlerChirpsRetrieve(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+func parseChirpsQuery(r *http.Request) (chirpsQuery, error) {
+	query := r.URL.Query()
+	q := chirpsQuery{sortType: query.Get("sort")}
+
+	if q.sortType != "" && q.sortType != "asc" && q.sortType != "desc" {
+		return chirpsQuery{}, errInvalidSort
+	}
 
-	var userID *uuid.UUID
-	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+	if authorID := query.Get("author_id"); authorID != "" {
 		id, err := uuid.Parse(authorID)
 		if err != nil {
-			response.WithError(w, http.StatusBadRequest, "Invalid author_id query parameter", err)
-			return
+			return chirpsQuery{}, errInvalidAuthorID
 		}
-		userID = &id
+		q.authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	limit, cursor, err := pagination.Parse(query)
+	if err != nil {
+		return chirpsQuery{}, err
+	}
+	q.limit = limit
+	q.cursor = cursor
+	return q, nil
+}
+
+func (cfg *apiConfig) handlerChirpsRetrieve(w http.ResponseWriter, r *http.Request) {
+	q, err := parseChirpsQuery(r)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
 	}
+	q.viewerID = cfg.viewerID(r)
 
-	chirps, err := getChirpInstances(ctx, userID, cfg.db)
+	chirps, err := getChirpInstances(r.Context(), cfg.db, q)
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve all instances from db", err)
 		return
 	}
-	sortType := r.URL.Query().Get("sort")
-	sortedChirps := getSortedChirps(chirps, sortType)
-
-	payload := []models.Chirp{}
-	for _, chirp := range sortedChirps {
-		payload = append(payload, models.Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
-		})
+
+	payload := chirpPage(chirps, q.limit)
+	if err := cfg.decorateChirps(r.Context(), q.viewerID, payload.Chirps); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
+		return
+	}
+	payload.Chirps, err = cfg.hideMutedWords(r.Context(), q.viewerID, payload.Chirps)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
 	}
 	response.WithJSON(w, http.StatusOK, payload)
 }
@@ -67,20 +106,113 @@ func (cfg *apiConfig) handlerChirpsGet(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
 		return
 	}
-	chirp, err := cfg.db.GetChirp(r.Context(), id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			response.WithError(w, http.StatusNotFound, "chirp not found", nil)
-			return
-		}
-		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve the single chirp instance from db", err)
+	viewerID := cfg.viewerID(r)
+	chirp, ok := cfg.getVisibleChirp(w, r, viewerID, id)
+	if !ok {
+		return
+	}
+	payload := []models.Chirp{chirpFromDB(chirp)}
+	if err := cfg.decorateChirps(r.Context(), viewerID, payload); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
 		return
 	}
-	response.WithJSON(w, http.StatusOK, models.Chirp{
+	response.WithJSON(w, http.StatusOK, payload[0])
+}
+
+func chirpFromDB(chirp database.Chirp) models.Chirp {
+	return models.Chirp{
 		ID:        chirp.ID,
 		CreatedAt: chirp.CreatedAt,
 		UpdatedAt: chirp.UpdatedAt,
 		Body:      chirp.Body,
 		UserID:    chirp.UserID,
-	})
+		InReplyTo: uuidPtr(chirp.InReplyTo),
+		Edited:    chirp.UpdatedAt.After(chirp.CreatedAt),
+		LikeCount: chirp.LikeCount,
+
+		Kind:              chirp.Kind,
+		ReferencedChirpID: uuidPtr(chirp.ReferencedChirpID),
+	}
+}
+
+// chirpPage converts a page fetched with one extra row into its payload, the
+// extra row only signals that there is a next page.
+func chirpPage(chirps []database.Chirp, limit int32) models.ChirpPage {
+	page := models.ChirpPage{Chirps: []models.Chirp{}}
+	if len(chirps) > int(limit) {
+		chirps = chirps[:limit]
+		last := chirps[len(chirps)-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, chirp := range chirps {
+		page.Chirps = append(page.Chirps, chirpFromDB(chirp))
+	}
+	return page
+}
+
+// decorateChirps fills in the fields of a chirp payload that don't live on the
+// chirps row itself.
+func (cfg *apiConfig) decorateChirps(ctx context.Context, viewerID uuid.NullUUID, chirps []models.Chirp) error {
+	if err := cfg.embedReferencedChirps(ctx, viewerID, chirps); err != nil {
+		return err
+	}
+	if err := cfg.markLikedByViewer(ctx, viewerID, chirps); err != nil {
+		return err
+	}
+	showOriginalLikes(chirps)
+	return nil
+}
+
+// getVisibleChirp loads a chirp by id and writes a 404 when the viewer can't
+// see it, see visibleChirp.
+func (cfg *apiConfig) getVisibleChirp(w http.ResponseWriter, r *http.Request, viewerID uuid.NullUUID, id uuid.UUID) (database.Chirp, bool) {
+	chirp, err := cfg.visibleChirp(r.Context(), viewerID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "chirp not found", nil)
+			return database.Chirp{}, false
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve the single chirp instance from db", err)
+		return database.Chirp{}, false
+	}
+	return chirp, true
+}
+
+// visibleChirp loads a chirp by id. Chirps of users that blocked the viewer,
+// or that the viewer blocked, and chirps of shadow-banned users other than the
+// viewer are reported as sql.ErrNoRows.
+func (cfg *apiConfig) visibleChirp(ctx context.Context, viewerID uuid.NullUUID, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.db.GetChirp(ctx, id)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if !viewerID.Valid || viewerID.UUID != chirp.UserID {
+		banned, err := cfg.db.IsShadowBanned(ctx, chirp.UserID)
+		if err != nil {
+			return database.Chirp{}, err
+		}
+		if banned {
+			return database.Chirp{}, sql.ErrNoRows
+		}
+	}
+	if viewerID.Valid {
+		blocked, err := cfg.db.IsBlockedBetween(ctx, database.IsBlockedBetweenParams{
+			UserID:  viewerID.UUID,
+			OtherID: chirp.UserID,
+		})
+		if err != nil {
+			return database.Chirp{}, err
+		}
+		if blocked {
+			return database.Chirp{}, sql.ErrNoRows
+		}
+	}
+	return chirp, nil
+}
+
+func uuidPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
 }
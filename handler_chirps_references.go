@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	chirpKindChirp   = "chirp"
+	chirpKindRechirp = "rechirp"
+	chirpKindQuote   = "quote"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (cfg *apiConfig) handlerRechirpsCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	original, ok := cfg.getReferencableChirp(w, r, userID)
+	if !ok {
+		return
+	}
+	if original.UserID == userID {
+		response.WithError(w, http.StatusBadRequest, "You can't rechirp your own chirp", nil)
+		return
+	}
+
+	embedded := chirpFromDB(original)
+	withOriginal := func(rechirp database.Chirp) models.Chirp {
+		payload := chirpFromDB(rechirp)
+		payload.ReferencedChirp = &embedded
+		return payload
+	}
+
+	referencedID := uuid.NullUUID{UUID: original.ID, Valid: true}
+	existing, err := cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+		UserID:            userID,
+		ReferencedChirpID: referencedID,
+	})
+	if err == nil {
+		response.WithJSON(w, http.StatusOK, withOriginal(existing))
+		return
+	}
+	if err != sql.ErrNoRows {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve rechirp", err)
+		return
+	}
+
+	rechirp, err := cfg.db.CreateChirpReference(r.Context(), database.CreateChirpReferenceParams{
+		Body:              "",
+		UserID:            userID,
+		Kind:              chirpKindRechirp,
+		ReferencedChirpID: referencedID,
+	})
+	if isUniqueViolation(err) {
+		// A concurrent request rechirped it first.
+		existing, err := cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+			UserID:            userID,
+			ReferencedChirpID: referencedID,
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve rechirp", err)
+			return
+		}
+		response.WithJSON(w, http.StatusOK, withOriginal(existing))
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create rechirp", err)
+		return
+	}
+	payload := withOriginal(rechirp)
+	cfg.publishChirp(r.Context(), payload)
+	response.WithJSON(w, http.StatusCreated, payload)
+}
+
+func (cfg *apiConfig) handlerRechirpsDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return
+	}
+
+	rechirp, err := cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+		UserID:            userID,
+		ReferencedChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "rechirp not found", nil)
+			return
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve rechirp", err)
+		return
+	}
+
+	err = cfg.db.DeleteChirp(r.Context(), rechirp.ID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't delete the rechirp", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerQuotesCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.QuoteChirpRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+
+	cleanedBody, err := cfg.validateChirp(params.Body)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	original, ok := cfg.getReferencableChirp(w, r, userID)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	quote, err := qtx.CreateChirpReference(r.Context(), database.CreateChirpReferenceParams{
+		Body:              cleanedBody,
+		UserID:            userID,
+		Kind:              chirpKindQuote,
+		ReferencedChirpID: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create quote", err)
+		return
+	}
+	mentioned, err := cfg.indexChirp(r.Context(), qtx, quote)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't index chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	payload := chirpFromDB(quote)
+	embedded := chirpFromDB(original)
+	payload.ReferencedChirp = &embedded
+	cfg.publishChirp(r.Context(), payload)
+	cfg.publishMentions(payload, mentioned)
+	response.WithJSON(w, http.StatusCreated, payload)
+}
+
+// getReferencableChirp loads the chirp named in the url. Rechirps are resolved
+// to the chirp they repost so references always point at original content.
+func (cfg *apiConfig) getReferencableChirp(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (database.Chirp, bool) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return database.Chirp{}, false
+	}
+
+	viewerID := uuid.NullUUID{UUID: userID, Valid: true}
+	chirp, ok := cfg.getVisibleChirp(w, r, viewerID, chirpID)
+	if !ok || chirp.Kind != chirpKindRechirp {
+		return chirp, ok
+	}
+	if !chirp.ReferencedChirpID.Valid {
+		response.WithError(w, http.StatusNotFound, "chirp not found", nil)
+		return database.Chirp{}, false
+	}
+	return cfg.getVisibleChirp(w, r, viewerID, chirp.ReferencedChirpID.UUID)
+}
+
+// embedReferencedChirps attaches the original chirp to every rechirp and quote.
+// References whose original has been deleted, or whose author is blocked
+// from the viewer, are flagged instead.
+func (cfg *apiConfig) embedReferencedChirps(ctx context.Context, viewerID uuid.NullUUID, chirps []models.Chirp) error {
+	var ids []uuid.UUID
+	for _, chirp := range chirps {
+		if chirp.ReferencedChirpID != nil {
+			ids = append(ids, *chirp.ReferencedChirpID)
+		}
+	}
+
+	referenced := map[uuid.UUID]database.Chirp{}
+	if len(ids) > 0 {
+		originals, err := cfg.db.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+			Ids:      ids,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return err
+		}
+		for _, original := range originals {
+			referenced[original.ID] = original
+		}
+	}
+
+	for i := range chirps {
+		if chirps[i].Kind == chirpKindChirp {
+			continue
+		}
+		if chirps[i].ReferencedChirpID == nil {
+			chirps[i].ReferencedChirpDeleted = true
+			continue
+		}
+		original, ok := referenced[*chirps[i].ReferencedChirpID]
+		if !ok {
+			chirps[i].ReferencedChirpDeleted = true
+			continue
+		}
+		embedded := chirpFromDB(original)
+		chirps[i].ReferencedChirp = &embedded
+	}
+	return nil
+}
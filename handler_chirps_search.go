@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/search"
+)
+
+func (cfg *apiConfig) handlerChirpsSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tsQuery, err := search.ToTSQuery(query.Get("q"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var authorID uuid.NullUUID
+	if author := query.Get("author_id"); author != "" {
+		id, err := uuid.Parse(author)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, errInvalidAuthorID.Error(), err)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	limit, err := pagination.ParseLimit(query.Get("limit"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var offset int32
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset, err = pagination.DecodeOffset(cursor)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	viewerID := cfg.viewerID(r)
+	results, err := cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:      tsQuery,
+		AuthorID:   authorID,
+		ViewerID:   viewerID,
+		PageSize:   limit + 1,
+		PageOffset: offset,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	payload := models.ChirpPage{Chirps: []models.Chirp{}}
+	if len(results) > int(limit) {
+		results = results[:limit]
+		// Past the largest offset there is no next page to point to.
+		if next := int64(offset) + int64(limit); next <= math.MaxInt32 {
+			payload.NextCursor = pagination.EncodeOffset(int32(next))
+		}
+	}
+	for _, result := range results {
+		payload.Chirps = append(payload.Chirps, chirpFromDB(result.Chirp))
+	}
+	if err := cfg.decorateChirps(r.Context(), viewerID, payload.Chirps); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
+		return
+	}
+	payload.Chirps, err = cfg.hideMutedWords(r.Context(), viewerID, payload.Chirps)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
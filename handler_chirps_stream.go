@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pubsub"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	chirpsTopicPrefix    = "chirps:"
+	streamBufferSize     = 64
+	streamHeartbeatEvery = 15 * time.Second
+)
+
+func chirpsTopic(userID uuid.UUID) string {
+	return chirpsTopicPrefix + userID.String()
+}
+
+// publishChirp pushes a newly created chirp to every live stream subscriber.
+// Chirps of shadow-banned users are only visible to their author, so they
+// are never published.
+func (cfg *apiConfig) publishChirp(ctx context.Context, chirp models.Chirp) {
+	banned, err := cfg.db.IsShadowBanned(ctx, chirp.UserID)
+	if err != nil {
+		log.Printf("Error checking shadow ban for stream: %s", err)
+		return
+	}
+	if banned {
+		return
+	}
+	data, err := json.Marshal(chirp)
+	if err != nil {
+		log.Printf("Error marshalling chirp for stream: %s", err)
+		return
+	}
+	cfg.events.Publish(chirpsTopic(chirp.UserID), data)
+}
+
+// chirpsTopicBlocked reports whether topic carries the chirps of an author
+// who has blocked the viewer or been blocked by them. It is checked when a
+// stream subscribes to a single author.
+func (cfg *apiConfig) chirpsTopicBlocked(ctx context.Context, viewerID uuid.NullUUID, topic string) (bool, error) {
+	if !viewerID.Valid {
+		return false, nil
+	}
+	id, found := strings.CutPrefix(topic, chirpsTopicPrefix)
+	if !found {
+		return false, nil
+	}
+	authorID, err := uuid.Parse(id)
+	if err != nil {
+		return false, err
+	}
+	return cfg.db.IsBlockedBetween(ctx, database.IsBlockedBetweenParams{
+		UserID:  viewerID.UUID,
+		OtherID: authorID,
+	})
+}
+
+// chirpEventHidden reports whether a chirps topic event should be withheld
+// from the viewer: its author, or the author of the chirp it references, is
+// blocked, muted or shadow-banned as seen by the viewer. These can change
+// while a stream is open, so it is checked for every event delivered.
+func (cfg *apiConfig) chirpEventHidden(ctx context.Context, viewerID uuid.NullUUID, event pubsub.Event) (bool, error) {
+	if !strings.HasPrefix(event.Topic, chirpsTopicPrefix) {
+		return false, nil
+	}
+	var chirp models.Chirp
+	if err := json.Unmarshal(event.Data, &chirp); err != nil {
+		return false, err
+	}
+	authorIDs := []uuid.UUID{chirp.UserID}
+	if chirp.ReferencedChirp != nil {
+		authorIDs = append(authorIDs, chirp.ReferencedChirp.UserID)
+	}
+	return cfg.db.IsHiddenFromAny(ctx, database.IsHiddenFromAnyParams{
+		ViewerID:  viewerID,
+		AuthorIds: authorIDs,
+	})
+}
+
+// publishMentions tells each mentioned user about the chirp on their private
+// mentions topic and delivers the matching notifications.
+func (cfg *apiConfig) publishMentions(chirp models.Chirp, mentions []database.Notification) {
+	if len(mentions) == 0 {
+		return
+	}
+	cfg.notifications.Publish(mentions...)
+	data, err := json.Marshal(chirp)
+	if err != nil {
+		log.Printf("Error marshalling chirp for stream: %s", err)
+		return
+	}
+	for _, mention := range mentions {
+		cfg.events.Publish(mentionsTopic(mention.UserID), data)
+	}
+}
+
+func (cfg *apiConfig) handlerChirpsStream(w http.ResponseWriter, r *http.Request) {
+	viewerID := cfg.viewerID(r)
+	match := func(topic string) bool { return strings.HasPrefix(topic, chirpsTopicPrefix) }
+	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+		id, err := uuid.Parse(authorID)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, errInvalidAuthorID.Error(), err)
+			return
+		}
+		topic := chirpsTopic(id)
+		blocked, err := cfg.chirpsTopicBlocked(r.Context(), viewerID, topic)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't check blocks", err)
+			return
+		}
+		if blocked {
+			response.WithError(w, http.StatusNotFound, "author not found", nil)
+			return
+		}
+		match = func(t string) bool { return t == topic }
+	}
+
+	var lastEventID uint64
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		parsed, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, "Invalid Last-Event-ID header", err)
+			return
+		}
+		lastEventID = parsed
+	}
+
+	rc := http.NewResponseController(w)
+	sub, backlog := cfg.events.Subscribe(lastEventID, streamBufferSize, match)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range backlog {
+		if err := cfg.writeStreamEvent(r.Context(), w, viewerID, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("Error flushing chirp stream: %s", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatEvery)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			// The client is expected to reconnect with Last-Event-ID.
+			return
+		case event := <-sub.Events():
+			if err := cfg.writeStreamEvent(r.Context(), w, viewerID, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStreamEvent writes event unless it is hidden from the viewer.
+func (cfg *apiConfig) writeStreamEvent(ctx context.Context, w http.ResponseWriter, viewerID uuid.NullUUID, event pubsub.Event) error {
+	hidden, err := cfg.chirpEventHidden(ctx, viewerID, event)
+	if err != nil || hidden {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: chirp\ndata: %s\n\n", event.ID, event.Data)
+	return err
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+	maxAncestorDepth   = 50
+	maxThreadReplies   = 500
+)
+
+var errInvalidDepth = errors.New("depth must be between 1 and " + strconv.Itoa(maxThreadDepth))
+
+func (cfg *apiConfig) handlerChirpsThread(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return
+	}
+
+	depth := defaultThreadDepth
+	if d := r.URL.Query().Get("depth"); d != "" {
+		depth, err = strconv.Atoi(d)
+		if err != nil || depth < 1 || depth > maxThreadDepth {
+			response.WithError(w, http.StatusBadRequest, errInvalidDepth.Error(), errInvalidDepth)
+			return
+		}
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirp, ok := cfg.getVisibleChirp(w, r, viewerID, chirpID)
+	if !ok {
+		return
+	}
+
+	ancestors, err := cfg.db.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+		ChirpID:  chirpID,
+		MaxDepth: maxAncestorDepth,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp ancestors", err)
+		return
+	}
+
+	descendants, err := cfg.db.GetChirpDescendants(r.Context(), database.GetChirpDescendantsParams{
+		ChirpID:    chirpID,
+		MaxDepth:   int32(depth),
+		ViewerID:   viewerID,
+		MaxReplies: maxThreadReplies,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp replies", err)
+		return
+	}
+
+	// Ancestors, the chirp itself and the replies share one slice so likes can
+	// be resolved with a single query.
+	chirps := []models.Chirp{}
+	for _, ancestor := range ancestors {
+		chirps = append(chirps, chirpFromDB(ancestor.Chirp))
+	}
+	chirps = append(chirps, chirpFromDB(chirp))
+	for _, reply := range descendants {
+		chirps = append(chirps, chirpFromDB(reply.Chirp))
+	}
+	if err := cfg.decorateChirps(r.Context(), viewerID, chirps); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
+		return
+	}
+
+	thread := models.ChirpThread{
+		Ancestors: chirps[:len(ancestors)],
+		Chirp:     chirps[len(ancestors)],
+	}
+	// Hiding a reply also hides the replies beneath it.
+	replies, err := cfg.hideMutedWords(r.Context(), viewerID, chirps[len(ancestors)+1:])
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	children := map[uuid.UUID][]models.Chirp{}
+	for _, reply := range replies {
+		children[*reply.InReplyTo] = append(children[*reply.InReplyTo], reply)
+	}
+	thread.Replies = buildReplyTree(children, chirpID)
+
+	response.WithJSON(w, http.StatusOK, thread)
+}
+
+func buildReplyTree(children map[uuid.UUID][]models.Chirp, parentID uuid.UUID) []models.ChirpReply {
+	replies := []models.ChirpReply{}
+	for _, child := range children[parentID] {
+		replies = append(replies, models.ChirpReply{
+			Chirp:   child,
+			Replies: buildReplyTree(children, child.ID),
+		})
+	}
+	return replies
+}
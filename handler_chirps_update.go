@@ -0,0 +1,143 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+func (cfg *apiConfig) handlerChirpsUpdate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.UpdateChirpRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+
+	cleanedBody, err := cfg.validateChirp(params.Body)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.GetChirpForUpdate(r.Context(), chirpID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "chirp not found", nil)
+			return
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve the single chirp instance from db", err)
+		return
+	}
+
+	if chirp.UserID != userID {
+		response.WithError(w, http.StatusForbidden, "You can't do this", nil)
+		return
+	}
+	if chirp.Kind == chirpKindRechirp {
+		response.WithError(w, http.StatusBadRequest, "Rechirps can't be edited", nil)
+		return
+	}
+	if time.Since(chirp.CreatedAt) > cfg.config.ChirpEditWindow {
+		response.WithError(w, http.StatusForbidden, "Chirp can no longer be edited", nil)
+		return
+	}
+	if cleanedBody == chirp.Body {
+		response.WithJSON(w, http.StatusOK, chirpFromDB(chirp))
+		return
+	}
+
+	_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   chirp.ID,
+		Body:      chirp.Body,
+		CreatedAt: chirp.UpdatedAt,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't save chirp revision", err)
+		return
+	}
+
+	updated, err := qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		Body: cleanedBody,
+		ID:   chirp.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	mentioned, err := cfg.indexChirp(r.Context(), qtx, updated)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't index chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	payload := chirpFromDB(updated)
+	cfg.publishMentions(payload, mentioned)
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerChirpRevisionsGet(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return
+	}
+
+	if _, ok := cfg.getVisibleChirp(w, r, cfg.viewerID(r), chirpID); !ok {
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(r.Context(), chirpID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp revisions", err)
+		return
+	}
+
+	payload := []models.ChirpRevision{}
+	for _, revision := range revisions {
+		payload = append(payload, models.ChirpRevision{
+			ID:        revision.ID,
+			ChirpID:   revision.ChirpID,
+			Body:      revision.Body,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
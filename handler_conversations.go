@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+// maxConversationMembers includes the user that creates the conversation.
+const maxConversationMembers = 10
+
+func (cfg *apiConfig) handlerConversationsCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.CreateConversationRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+
+	memberIDs := []uuid.UUID{userID}
+	for _, id := range params.MemberIDs {
+		if !slices.Contains(memberIDs, id) {
+			memberIDs = append(memberIDs, id)
+		}
+	}
+	if len(memberIDs) < 2 {
+		response.WithError(w, http.StatusBadRequest, "A conversation needs at least one other member", nil)
+		return
+	}
+	if len(memberIDs) > maxConversationMembers {
+		response.WithError(w, http.StatusBadRequest, "Too many conversation members", nil)
+		return
+	}
+
+	users, err := cfg.db.GetUsersByIDs(r.Context(), memberIDs)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve users", err)
+		return
+	}
+	if len(users) != len(memberIDs) {
+		response.WithError(w, http.StatusBadRequest, "member not found", nil)
+		return
+	}
+	blocked, err := cfg.db.ListBlockedAmong(r.Context(), database.ListBlockedAmongParams{
+		UserID:  userID,
+		UserIds: memberIDs,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check blocks", err)
+		return
+	}
+	if len(blocked) > 0 {
+		response.WithError(w, http.StatusForbidden, "You can't message one of these users", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// There is only ever one 1:1 conversation between two users, so creating
+	// it again returns the existing one.
+	var directKey sql.NullString
+	if len(memberIDs) == 2 {
+		directKey = sql.NullString{String: directConversationKey(memberIDs[0], memberIDs[1]), Valid: true}
+	}
+	conversation, err := qtx.CreateConversation(r.Context(), directKey)
+	if err == sql.ErrNoRows {
+		existing, err := qtx.GetConversationByDirectKey(r.Context(), directKey)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversation", err)
+			return
+		}
+		cfg.respondWithConversation(w, r, http.StatusOK, existing)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create conversation", err)
+		return
+	}
+	err = qtx.AddConversationMembers(r.Context(), database.AddConversationMembersParams{
+		ConversationID: conversation.ID,
+		UserIds:        memberIDs,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't add conversation members", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusCreated, models.Conversation{
+		ID:        conversation.ID,
+		CreatedAt: conversation.CreatedAt,
+		UpdatedAt: conversation.UpdatedAt,
+		MemberIDs: memberIDs,
+	})
+}
+
+func directConversationKey(a, b uuid.UUID) string {
+	if b.String() < a.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+func (cfg *apiConfig) handlerConversationsList(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorUpdatedAt, cursorID := cursor.QueryArgs()
+	rows, err := cfg.db.ListConversations(r.Context(), database.ListConversationsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorUpdatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversations", err)
+		return
+	}
+
+	payload := models.ConversationPage{Conversations: []models.Conversation{}}
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1].Conversation
+		payload.NextCursor = pagination.Cursor{CreatedAt: last.UpdatedAt, ID: last.ID}.Encode()
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.Conversation.ID)
+	}
+	members, err := cfg.conversationMemberIDs(r.Context(), ids)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversation members", err)
+		return
+	}
+	for _, row := range rows {
+		payload.Conversations = append(payload.Conversations, models.Conversation{
+			ID:          row.Conversation.ID,
+			CreatedAt:   row.Conversation.CreatedAt,
+			UpdatedAt:   row.Conversation.UpdatedAt,
+			MemberIDs:   members[row.Conversation.ID],
+			UnreadCount: row.UnreadCount,
+		})
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+// getConversationMember authorizes access to the conversation named in the
+// url. Non-members get a 404 so conversation ids can't be probed.
+func (cfg *apiConfig) getConversationMember(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (database.ConversationMember, bool) {
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid conversationID in the url", err)
+		return database.ConversationMember{}, false
+	}
+
+	member, err := cfg.db.GetConversationMember(r.Context(), database.GetConversationMemberParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "conversation not found", nil)
+			return database.ConversationMember{}, false
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversation member", err)
+		return database.ConversationMember{}, false
+	}
+	return member, true
+}
+
+func (cfg *apiConfig) conversationMemberIDs(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	members := map[uuid.UUID][]uuid.UUID{}
+	if len(conversationIDs) == 0 {
+		return members, nil
+	}
+	rows, err := cfg.db.ListConversationMembers(ctx, conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		members[row.ConversationID] = append(members[row.ConversationID], row.UserID)
+	}
+	return members, nil
+}
+
+func (cfg *apiConfig) respondWithConversation(w http.ResponseWriter, r *http.Request, status int, conversation database.Conversation) {
+	members, err := cfg.conversationMemberIDs(r.Context(), []uuid.UUID{conversation.ID})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversation members", err)
+		return
+	}
+	response.WithJSON(w, status, models.Conversation{
+		ID:        conversation.ID,
+		CreatedAt: conversation.CreatedAt,
+		UpdatedAt: conversation.UpdatedAt,
+		MemberIDs: members[conversation.ID],
+	})
+}
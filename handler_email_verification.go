@@ -0,0 +1,224 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/mailer"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	emailVerificationTTL       = 24 * time.Hour
+	emailVerificationWindow    = time.Hour
+	maxEmailVerificationTokens = 3
+)
+
+// startEmailVerification issues a token proving ownership of email and
+// returns the message carrying it, to be sent once q's transaction commits.
+// Tokens issued earlier for the user stop working, so only the most recently
+// requested address can be confirmed.
+func (cfg *apiConfig) startEmailVerification(r *http.Request, q *database.Queries, userID uuid.UUID, email string) (mailer.Message, error) {
+	if err := q.UseEmailVerificationTokens(r.Context(), userID); err != nil {
+		return mailer.Message{}, err
+	}
+
+	token, err := auth.MakeToken()
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	err = q.CreateEmailVerificationToken(r.Context(), database.CreateEmailVerificationTokenParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    userID,
+		Email:     email,
+		ExpiresAt: time.Now().UTC().Add(emailVerificationTTL),
+	})
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	err = cfg.audit.Record(r.Context(), q, r, audit.Event{
+		Action:   audit.ActionEmailVerifySent,
+		ActorID:  actor,
+		TargetID: actor,
+		Details:  map[string]any{"email": email},
+	})
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	link := cfg.config.PublicURL + "/verify-email?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      email,
+		Subject: "Confirm your Chirpy email address",
+		Body: fmt.Sprintf(
+			"Open this link within %s to confirm this address for your Chirpy account:\n%s\n\n"+
+				"If you didn't ask for this, you can ignore this email.\n",
+			emailVerificationTTL, link,
+		),
+	}, nil
+}
+
+func (cfg *apiConfig) handlerEmailVerify(w http.ResponseWriter, r *http.Request) {
+	var params models.VerifyEmailRequest
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Token == "" {
+		response.WithError(w, http.StatusBadRequest, "token is required", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verification, err := qtx.GetEmailVerificationTokenForUpdate(r.Context(), auth.HashToken(params.Token))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve verification token", err)
+		return
+	}
+	if err != nil || verification.UsedAt.Valid || time.Now().UTC().After(verification.ExpiresAt) {
+		response.WithError(w, http.StatusBadRequest, "Invalid or expired verification token", err)
+		return
+	}
+
+	oldUser, err := qtx.GetUserByID(r.Context(), verification.UserID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	user, err := qtx.VerifyUserEmail(r.Context(), database.VerifyUserEmailParams{
+		Email: verification.Email,
+		ID:    verification.UserID,
+	})
+	if isUniqueViolation(err) {
+		response.WithError(w, http.StatusConflict, "Email already in use", err)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+	if err := qtx.UseEmailVerificationTokens(r.Context(), user.ID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't invalidate verification tokens", err)
+		return
+	}
+
+	actor := uuid.NullUUID{UUID: user.ID, Valid: true}
+	events := []audit.Event{{
+		Action:   audit.ActionEmailVerified,
+		ActorID:  actor,
+		TargetID: actor,
+		Details:  map[string]any{"email": user.Email},
+	}}
+	if oldUser.Email != user.Email {
+		events = append(events, audit.Event{
+			Action:   audit.ActionEmailChanged,
+			ActorID:  actor,
+			TargetID: actor,
+			Details:  map[string]any{"old_email": oldUser.Email, "new_email": user.Email},
+		})
+	}
+	for _, event := range events {
+		if err := cfg.audit.Record(r.Context(), qtx, r, event); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, userFromDB(user))
+}
+
+// handlerEmailVerificationResend sends a fresh token for the pending email
+// change if there is one, and otherwise for the current unverified address.
+func (cfg *apiConfig) handlerEmailVerificationResend(w http.ResponseWriter, r *http.Request) {
+	if cfg.mailer == nil {
+		response.WithError(w, http.StatusServiceUnavailable, "Email verification is disabled", nil)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	email, err := cfg.db.GetPendingEmail(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if user.EmailVerifiedAt.Valid {
+			response.WithError(w, http.StatusConflict, "Email already verified", nil)
+			return
+		}
+		email, err = user.Email, nil
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve pending email", err)
+		return
+	}
+
+	recent, err := cfg.db.CountRecentEmailVerificationTokens(r.Context(), database.CountRecentEmailVerificationTokensParams{
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Add(-emailVerificationWindow),
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check verification tokens", err)
+		return
+	}
+	if recent >= maxEmailVerificationTokens {
+		response.WithError(w, http.StatusTooManyRequests, "Too many verification emails, try again later", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+
+	msg, err := cfg.startEmailVerification(r, cfg.db.WithTx(tx), userID, email)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	cfg.sendEmail(msg)
+	w.WriteHeader(http.StatusAccepted)
+}
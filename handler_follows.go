@@ -0,0 +1,206 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/notifications"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+func (cfg *apiConfig) handlerFollowCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	followerID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	followee, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	if followee.ID == followerID {
+		response.WithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+	blocked, err := cfg.db.IsBlockedBetween(r.Context(), database.IsBlockedBetweenParams{
+		UserID:  followerID,
+		OtherID: followee.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check blocks", err)
+		return
+	}
+	if blocked {
+		response.WithError(w, http.StatusForbidden, "You can't follow this user", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	created, err := qtx.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+		return
+	}
+
+	var notified []database.Notification
+	if created > 0 {
+		notification, ok, err := cfg.notifications.Record(r.Context(), qtx, notifications.Notification{
+			UserID:  followee.ID,
+			ActorID: uuid.NullUUID{UUID: followerID, Valid: true},
+			Kind:    notifications.KindFollow,
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't record notification", err)
+			return
+		}
+		if ok {
+			notified = append(notified, notification)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	cfg.notifications.Publish(notified...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerFollowDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	followerID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid userID in the url", err)
+		return
+	}
+
+	_, err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't unfollow user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerFollowersGet(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	followers, err := cfg.db.ListFollowers(r.Context(), database.ListFollowersParams{
+		UserID:          user.ID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve followers", err)
+		return
+	}
+
+	follows := make([]models.Follow, 0, len(followers))
+	for _, follower := range followers {
+		follows = append(follows, models.Follow{UserID: follower.FollowerID, CreatedAt: follower.CreatedAt})
+	}
+	response.WithJSON(w, http.StatusOK, followPage(follows, limit))
+}
+
+func (cfg *apiConfig) handlerFollowingGet(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	following, err := cfg.db.ListFollowing(r.Context(), database.ListFollowingParams{
+		UserID:          user.ID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve followed users", err)
+		return
+	}
+
+	follows := make([]models.Follow, 0, len(following))
+	for _, followee := range following {
+		follows = append(follows, models.Follow{UserID: followee.FolloweeID, CreatedAt: followee.CreatedAt})
+	}
+	response.WithJSON(w, http.StatusOK, followPage(follows, limit))
+}
+
+func followPage(follows []models.Follow, limit int32) models.FollowPage {
+	page := models.FollowPage{Users: follows}
+	if len(follows) > int(limit) {
+		page.Users = follows[:limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.UserID}.Encode()
+	}
+	return page
+}
+
+// getPathUser loads the user named by the userID path value.
+func (cfg *apiConfig) getPathUser(w http.ResponseWriter, r *http.Request) (database.User, bool) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid userID in the url", err)
+		return database.User{}, false
+	}
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "User not found", nil)
+			return database.User{}, false
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return database.User{}, false
+	}
+	return user, true
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/entities"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+)
+
+var errInvalidWindow = errors.New("window must be a duration between 1m and 168h")
+
+func (cfg *apiConfig) handlerHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tag, ok := entities.NormalizeHashtag(r.PathValue("tag"))
+	if !ok {
+		response.WithError(w, http.StatusBadRequest, "Invalid hashtag in the url", nil)
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	chirps, err := cfg.db.ListHashtagChirps(r.Context(), database.ListHashtagChirpsParams{
+		Tag:             tag,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		ViewerID:        viewerID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve hashtag chirps", err)
+		return
+	}
+
+	payload := chirpPage(chirps, limit)
+	if err := cfg.decorateChirps(r.Context(), viewerID, payload.Chirps); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
+		return
+	}
+	payload.Chirps, err = cfg.hideMutedWords(r.Context(), viewerID, payload.Chirps)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+// handlerHashtagsTrending ranks hashtags used within the trailing window by
+// how many distinct authors used them, so one account can't push a tag alone.
+func (cfg *apiConfig) handlerHashtagsTrending(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	window := defaultTrendingWindow
+	if raw := query.Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < time.Minute || parsed > maxTrendingWindow {
+			response.WithError(w, http.StatusBadRequest, errInvalidWindow.Error(), err)
+			return
+		}
+		window = parsed
+	}
+
+	limit := int32(defaultTrendingLimit)
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := pagination.ParseLimit(raw)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		limit = parsed
+	}
+
+	since := time.Now().Add(-window)
+	trending, err := cfg.db.ListTrendingHashtags(r.Context(), database.ListTrendingHashtagsParams{
+		Since:    since,
+		PageSize: limit,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve trending hashtags", err)
+		return
+	}
+
+	payload := models.TrendingHashtags{Since: since, Hashtags: []models.HashtagTrend{}}
+	for _, trend := range trending {
+		payload.Hashtags = append(payload.Hashtags, models.HashtagTrend{
+			Tag:         trend.Tag,
+			ChirpCount:  trend.ChirpCount,
+			AuthorCount: trend.AuthorCount,
+		})
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
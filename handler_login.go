@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
 	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/database"
 	"github.com/markoc1120/go_server/internal/models"
@@ -34,47 +36,78 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 
 	user, err := cfg.db.GetUser(r.Context(), params.Email)
 	if err != nil {
+		cfg.audit.Log(r, loginFailed(uuid.NullUUID{}, params.Email, "unknown_email"))
 		response.WithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
 		return
 	}
+	userID := uuid.NullUUID{UUID: user.ID, Valid: true}
 
 	err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
 	if err != nil {
+		cfg.audit.Log(r, loginFailed(userID, params.Email, "wrong_password"))
 		response.WithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.config.Secret, time.Hour)
+	if cfg.refuseSuspended(w, r, user.ID) {
+		cfg.audit.Log(r, loginFailed(userID, params.Email, "suspended"))
+		return
+	}
+
+	enabled, err := cfg.twoFactorEnabled(r.Context(), user.ID)
 	if err != nil {
-		response.WithError(w, http.StatusInternalServerError, "Error generating JWT accessToken", err)
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check two-factor authentication", err)
 		return
 	}
+	if enabled {
+		challenge, err := auth.MakeChallengeJWT(user.ID, cfg.config.Secret, twoFactorChallengeTTL)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Error generating challenge token", err)
+			return
+		}
+		response.WithJSON(w, http.StatusAccepted, models.TwoFactorChallenge{
+			ChallengeToken: challenge,
+			ExpiresAt:      time.Now().UTC().Add(twoFactorChallengeTTL),
+		})
+		return
+	}
+
+	cfg.completeLogin(w, r, user, nil)
+}
 
-	refreshToken, err := auth.MakeRefreshToken()
+// completeLogin issues tokens for a user who has passed every check.
+func (cfg *apiConfig) completeLogin(w http.ResponseWriter, r *http.Request, user database.User, details map[string]any) {
+	accessToken, err := auth.MakeJWT(user.ID, auth.Role(user.Role), cfg.config.Secret, time.Hour)
 	if err != nil {
-		response.WithError(w, http.StatusInternalServerError, "Error generating refreshToken", err)
+		response.WithError(w, http.StatusInternalServerError, "Error generating JWT accessToken", err)
 		return
 	}
 
-	_, err = cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 60),
-	})
+	refreshToken, err := cfg.startSession(r, user.ID)
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
 		return
 	}
 
+	userID := uuid.NullUUID{UUID: user.ID, Valid: true}
+	cfg.audit.Log(r, audit.Event{
+		Action:   audit.ActionLoginSucceeded,
+		ActorID:  userID,
+		TargetID: userID,
+		Details:  details,
+	})
 	response.WithJSON(w, http.StatusOK, models.LoggedInUser{
-		User: models.User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User:         userFromDB(user),
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 	})
 }
+
+func loginFailed(userID uuid.NullUUID, email, reason string) audit.Event {
+	return audit.Event{
+		Action:   audit.ActionLoginFailed,
+		ActorID:  userID,
+		TargetID: userID,
+		Details:  map[string]any{"email": email, "reason": reason},
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+func (cfg *apiConfig) handlerMentionsGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	chirps, err := cfg.db.ListMentionedChirps(r.Context(), database.ListMentionedChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve mentions", err)
+		return
+	}
+
+	payload := chirpPage(chirps, limit)
+	if err := cfg.decorateChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, payload.Chirps); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp details", err)
+		return
+	}
+	payload.Chirps, err = cfg.hideMutedWords(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, payload.Chirps)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const maxMessageLength = 1000
+
+func messagesTopic(userID uuid.UUID) string {
+	return "messages:" + userID.String()
+}
+
+func messageFromDB(message database.Message) models.Message {
+	return models.Message{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		CreatedAt:      message.CreatedAt,
+	}
+}
+
+func validateMessage(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("Message can't be empty")
+	}
+	if len(body) > maxMessageLength {
+		return errors.New("Message is too long")
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerMessagesCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	member, ok := cfg.getConversationMember(w, r, userID)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.SendMessageRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	if err := validateMessage(params.Body); err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Blocking someone ends direct conversations with them, group
+	// conversations carry on for everybody else.
+	members, err := cfg.conversationMemberIDs(r.Context(), []uuid.UUID{member.ConversationID})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve conversation members", err)
+		return
+	}
+	if memberIDs := members[member.ConversationID]; len(memberIDs) == 2 {
+		blocked, err := cfg.db.ListBlockedAmong(r.Context(), database.ListBlockedAmongParams{
+			UserID:  userID,
+			UserIds: memberIDs,
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't check blocks", err)
+			return
+		}
+		if len(blocked) > 0 {
+			response.WithError(w, http.StatusForbidden, "You can't message this user", nil)
+			return
+		}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	message, err := qtx.CreateMessage(r.Context(), database.CreateMessageParams{
+		ConversationID: member.ConversationID,
+		SenderID:       userID,
+		Body:           params.Body,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't send message", err)
+		return
+	}
+	if err := qtx.TouchConversation(r.Context(), member.ConversationID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update conversation", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	payload := messageFromDB(message)
+	cfg.publishMessage(r.Context(), payload)
+	response.WithJSON(w, http.StatusCreated, payload)
+}
+
+func (cfg *apiConfig) handlerMessagesList(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	member, ok := cfg.getConversationMember(w, r, userID)
+	if !ok {
+		return
+	}
+
+	limit, cursor, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	messages, err := cfg.db.ListMessages(r.Context(), database.ListMessagesParams{
+		ConversationID:  member.ConversationID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve messages", err)
+		return
+	}
+
+	payload := models.MessagePage{Messages: []models.Message{}}
+	if len(messages) > int(limit) {
+		messages = messages[:limit]
+		last := messages[len(messages)-1]
+		payload.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, message := range messages {
+		payload.Messages = append(payload.Messages, messageFromDB(message))
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerConversationRead(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	member, ok := cfg.getConversationMember(w, r, userID)
+	if !ok {
+		return
+	}
+
+	err = cfg.db.MarkConversationRead(r.Context(), database.MarkConversationReadParams{
+		ConversationID: member.ConversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't mark conversation as read", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishMessage pushes a new message to the live streams of the other
+// conversation members.
+func (cfg *apiConfig) publishMessage(ctx context.Context, message models.Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshalling message for stream: %s", err)
+		return
+	}
+	members, err := cfg.conversationMemberIDs(ctx, []uuid.UUID{message.ConversationID})
+	if err != nil {
+		log.Printf("Error retrieving conversation members: %s", err)
+		return
+	}
+	for _, memberID := range members[message.ConversationID] {
+		if memberID != message.SenderID {
+			cfg.events.Publish(messagesTopic(memberID), data)
+		}
+	}
+}
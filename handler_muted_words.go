@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/textmatch"
+)
+
+const (
+	maxMutedWords      = 200
+	maxMutedWordLength = 100
+)
+
+func mutedWordFromDB(word database.MutedWord) models.MutedWord {
+	mutedWord := models.MutedWord{
+		ID:        word.ID,
+		Phrase:    word.Phrase,
+		WholeWord: word.WholeWord,
+		CreatedAt: word.CreatedAt,
+	}
+	if word.ExpiresAt.Valid {
+		mutedWord.ExpiresAt = &word.ExpiresAt.Time
+	}
+	return mutedWord
+}
+
+func (cfg *apiConfig) handlerMutedWordsCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.MuteWordRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+
+	// Phrases are stored normalized so "Spoiler!" and "spoiler" are one entry.
+	phrase := textmatch.Normalize(params.Phrase)
+	if phrase == "" {
+		response.WithError(w, http.StatusBadRequest, "Phrase must contain at least one word", nil)
+		return
+	}
+	if len(phrase) > maxMutedWordLength {
+		response.WithError(w, http.StatusBadRequest, "Phrase is too long", nil)
+		return
+	}
+	wholeWord := true
+	if params.WholeWord != nil {
+		wholeWord = *params.WholeWord
+	}
+	var expiresAt sql.NullTime
+	if params.ExpiresAt != nil {
+		if !params.ExpiresAt.After(time.Now()) {
+			response.WithError(w, http.StatusBadRequest, "expires_at must be in the future", nil)
+			return
+		}
+		expiresAt = sql.NullTime{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	count, err := cfg.db.CountActiveMutedWords(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't count muted words", err)
+		return
+	}
+	if count >= maxMutedWords {
+		response.WithError(w, http.StatusBadRequest, "Too many muted words", nil)
+		return
+	}
+
+	word, err := cfg.db.UpsertMutedWord(r.Context(), database.UpsertMutedWordParams{
+		UserID:    userID,
+		Phrase:    phrase,
+		WholeWord: wholeWord,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't mute word", err)
+		return
+	}
+	response.WithJSON(w, http.StatusCreated, mutedWordFromDB(word))
+}
+
+func (cfg *apiConfig) handlerMutedWordsGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	words, err := cfg.db.ListActiveMutedWords(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	payload := make([]models.MutedWord, 0, len(words))
+	for _, word := range words {
+		payload = append(payload, mutedWordFromDB(word))
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerMutedWordsDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	wordID, err := uuid.Parse(r.PathValue("mutedWordID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid mutedWordID in the url", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteMutedWord(r.Context(), database.DeleteMutedWordParams{
+		ID:     wordID,
+		UserID: userID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't unmute word", err)
+		return
+	}
+	if deleted == 0 {
+		response.WithError(w, http.StatusNotFound, "muted word not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hideMutedWords drops chirps containing one of the viewer's muted words,
+// including rechirps and quotes of such chirps. The viewer's own chirps are
+// always kept. Filtering happens after a page is fetched, so pages can be
+// shorter than requested while the cursor still moves past every row.
+func (cfg *apiConfig) hideMutedWords(ctx context.Context, viewerID uuid.NullUUID, chirps []models.Chirp) ([]models.Chirp, error) {
+	if !viewerID.Valid || len(chirps) == 0 {
+		return chirps, nil
+	}
+	words, err := cfg.db.ListActiveMutedWords(ctx, viewerID.UUID)
+	if err != nil || len(words) == 0 {
+		return chirps, err
+	}
+
+	rules := make([]textmatch.Rule, 0, len(words))
+	for _, word := range words {
+		rules = append(rules, textmatch.Rule{Phrase: word.Phrase, WholeWord: word.WholeWord})
+	}
+	matcher := textmatch.New(rules)
+	return slices.DeleteFunc(chirps, func(chirp models.Chirp) bool {
+		if chirp.UserID == viewerID.UUID {
+			return false
+		}
+		if matcher.Match(chirp.Body) {
+			return true
+		}
+		return chirp.ReferencedChirp != nil && matcher.Match(chirp.ReferencedChirp.Body)
+	}), nil
+}
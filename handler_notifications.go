@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const maxMarkReadIDs = 500
+
+func (cfg *apiConfig) handlerNotificationsGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, cursor, err := pagination.Parse(query)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var unreadOnly bool
+	if unread := query.Get("unread"); unread != "" {
+		unreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			response.WithError(w, http.StatusBadRequest, "unread must be true or false", err)
+			return
+		}
+	}
+
+	page, err := cfg.notifications.List(r.Context(), userID, unreadOnly, limit, cursor)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve notifications", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, page)
+}
+
+func (cfg *apiConfig) handlerNotificationsUnreadCount(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	count, err := cfg.notifications.UnreadCount(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't count unread notifications", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, models.UnreadNotifications{UnreadCount: count})
+}
+
+func (cfg *apiConfig) handlerNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.MarkNotificationsReadRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	if params.All == (len(params.IDs) > 0) {
+		response.WithError(w, http.StatusBadRequest, "Provide either ids or all", nil)
+		return
+	}
+	if len(params.IDs) > maxMarkReadIDs {
+		response.WithError(w, http.StatusBadRequest, "Too many ids", nil)
+		return
+	}
+
+	ids := params.IDs
+	if params.All {
+		ids = nil
+	}
+	marked, err := cfg.notifications.MarkRead(r.Context(), userID, ids)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't mark notifications as read", err)
+		return
+	}
+	count, err := cfg.notifications.UnreadCount(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't count unread notifications", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, models.MarkNotificationsReadResponse{
+		MarkedRead:  marked,
+		UnreadCount: count,
+	})
+}
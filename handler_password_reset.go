@@ -0,0 +1,189 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/mailer"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/validation"
+)
+
+const (
+	passwordResetTTL       = time.Hour
+	passwordResetWindow    = time.Hour
+	maxPasswordResetTokens = 3
+)
+
+// handlerPasswordForgot answers 202 whether or not the email belongs to an
+// account, so it can't be used to find out who has signed up.
+func (cfg *apiConfig) handlerPasswordForgot(w http.ResponseWriter, r *http.Request) {
+	if cfg.mailer == nil {
+		response.WithError(w, http.StatusServiceUnavailable, "Password reset is disabled", nil)
+		return
+	}
+
+	var params models.ForgotPasswordRequest
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	if err := validation.ValidateEmail(params.Email); err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+
+	recent, err := cfg.db.CountRecentPasswordResetTokens(r.Context(), database.CountRecentPasswordResetTokensParams{
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Add(-passwordResetWindow),
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check reset tokens", err)
+		return
+	}
+	if recent >= maxPasswordResetTokens {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	token, err := auth.MakeToken()
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't generate reset token", err)
+		return
+	}
+	err = cfg.db.CreatePasswordResetToken(r.Context(), database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(passwordResetTTL),
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't save reset token", err)
+		return
+	}
+
+	userID := uuid.NullUUID{UUID: user.ID, Valid: true}
+	cfg.audit.Log(r, audit.Event{
+		Action:   audit.ActionPasswordResetSent,
+		ActorID:  userID,
+		TargetID: userID,
+	})
+
+	cfg.sendEmail(cfg.passwordResetMessage(user.Email, token))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (cfg *apiConfig) passwordResetMessage(to, token string) mailer.Message {
+	link := cfg.config.PublicURL + "/reset-password?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      to,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password for your Chirpy account.\n\n"+
+				"Open this link within %s to choose a new one:\n%s\n\n"+
+				"If it wasn't you, you can ignore this email.\n",
+			passwordResetTTL, link,
+		),
+	}
+}
+
+func (cfg *apiConfig) handlerPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var params models.ResetPasswordRequest
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	if params.Token == "" {
+		response.WithError(w, http.StatusBadRequest, "token is required", nil)
+		return
+	}
+	if err := validation.ValidatePassword(params.Password); err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(params.Password)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Error during hashing password", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	resetToken, err := qtx.GetPasswordResetTokenForUpdate(r.Context(), auth.HashToken(params.Token))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve reset token", err)
+		return
+	}
+	if err != nil || resetToken.UsedAt.Valid || time.Now().UTC().After(resetToken.ExpiresAt) {
+		response.WithError(w, http.StatusBadRequest, "Invalid or expired reset token", err)
+		return
+	}
+
+	_, err = qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+		HashedPassword: passwordHash,
+		ID:             resetToken.UserID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update password", err)
+		return
+	}
+	if err := qtx.UsePasswordResetTokens(r.Context(), resetToken.UserID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't invalidate reset tokens", err)
+		return
+	}
+	if err := qtx.RevokeUserRefreshTokens(r.Context(), resetToken.UserID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't revoke refresh tokens", err)
+		return
+	}
+
+	userID := uuid.NullUUID{UUID: resetToken.UserID, Valid: true}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionPasswordReset,
+		ActorID:  userID,
+		TargetID: userID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,8 +6,10 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
 	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/notifications"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
@@ -34,16 +36,61 @@ func (cfg *apiConfig) handlerPolkaWebhooks(w http.ResponseWriter, r *http.Reques
 		response.WithError(w, http.StatusInternalServerError, "Couldn't parse user_id to uuid.UUID", err)
 		return
 	}
-	if params.Event == "user.upgraded" {
-		err = cfg.db.UpdateUserToChirpyRed(r.Context(), userID)
-		if err == sql.ErrNoRows {
-			response.WithError(w, http.StatusNotFound, "User not found", nil)
-			return
-		}
-		if err != nil {
-			response.WithError(w, http.StatusInternalServerError, "Couldn't update user to chirpy_red", err)
-			return
-		}
+	if params.Event != "user.upgraded" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.GetUserByID(r.Context(), userID)
+	if err == sql.ErrNoRows {
+		response.WithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	// Polka retries deliveries, only the first one should notify the user.
+	if user.IsChirpyRed {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = qtx.UpdateUserToChirpyRed(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update user to chirpy_red", err)
+		return
+	}
+	notification, _, err := cfg.notifications.Record(r.Context(), qtx, notifications.Notification{
+		UserID: userID,
+		Kind:   notifications.KindChirpyRed,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record notification", err)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionUserUpgraded,
+		TargetID: uuid.NullUUID{UUID: userID, Valid: true},
+		Details:  map[string]any{"source": "polka"},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
 	}
+	cfg.notifications.Publish(notification)
 	w.WriteHeader(http.StatusNoContent)
 }
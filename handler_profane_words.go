@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/textmatch"
+)
+
+func profaneWordFromDB(word database.ProfaneWord) models.ProfaneWord {
+	return models.ProfaneWord{
+		ID:        word.ID,
+		Word:      word.Word,
+		WholeWord: word.WholeWord,
+		CreatedAt: word.CreatedAt,
+	}
+}
+
+func (cfg *apiConfig) handlerProfaneWordsGet(w http.ResponseWriter, r *http.Request) {
+	words, err := cfg.db.ListProfaneWords(r.Context())
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve profane words", err)
+		return
+	}
+	payload := make([]models.ProfaneWord, 0, len(words))
+	for _, word := range words {
+		payload = append(payload, profaneWordFromDB(word))
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerProfaneWordsCreate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.CreateProfaneWordRequest{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+
+	word := textmatch.Normalize(params.Word)
+	if word == "" {
+		response.WithError(w, http.StatusBadRequest, "Word must contain at least one letter, digit or symbol", nil)
+		return
+	}
+	wholeWord := true
+	if params.WholeWord != nil {
+		wholeWord = *params.WholeWord
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	profaneWord, err := qtx.UpsertProfaneWord(r.Context(), database.UpsertProfaneWordParams{
+		Word:      word,
+		WholeWord: wholeWord,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't add profane word", err)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionProfaneWordAdded,
+		ActorID:  uuid.NullUUID{UUID: claims.UserID, Valid: true},
+		TargetID: uuid.NullUUID{UUID: profaneWord.ID, Valid: true},
+		Details:  map[string]any{"word": profaneWord.Word, "whole_word": profaneWord.WholeWord},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	if err := cfg.profanity.Reload(r.Context()); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't reload profane words", err)
+		return
+	}
+	response.WithJSON(w, http.StatusCreated, profaneWordFromDB(profaneWord))
+}
+
+func (cfg *apiConfig) handlerProfaneWordsDelete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	wordID, err := uuid.Parse(r.PathValue("wordID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid wordID in the url", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	deleted, err := qtx.DeleteProfaneWord(r.Context(), wordID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't delete profane word", err)
+		return
+	}
+	if deleted == 0 {
+		response.WithError(w, http.StatusNotFound, "profane word not found", nil)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionProfaneWordRemoved,
+		ActorID:  uuid.NullUUID{UUID: claims.UserID, Valid: true},
+		TargetID: uuid.NullUUID{UUID: wordID, Valid: true},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	if err := cfg.profanity.Reload(r.Context()); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't reload profane words", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,32 +1,150 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
 	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
 	"github.com/markoc1120/go_server/internal/models"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
+const refreshTokenTTL = 60 * 24 * time.Hour
+
+// issueRefreshToken creates a refresh token in the family of sessionID and
+// returns it. Only its hash is stored.
+func issueRefreshToken(ctx context.Context, q *database.Queries, userID, sessionID uuid.UUID) (string, error) {
+	refreshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		TokenHash: auth.HashRefreshToken(refreshToken),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		FamilyID:  sessionID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// handlerRefresh exchanges a refresh token for a new access token and a new
+// refresh token. The old refresh token stops working, presenting it again
+// means it was copied, so every token of its family is revoked.
 func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := auth.GetBearerToken(r.Header)
 	if err != nil {
 		response.WithError(w, http.StatusBadRequest, "Couldn't find token", err)
 		return
 	}
-	user, err := cfg.db.GetUserFromRefreshToken(r.Context(), refreshToken)
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	tokenHash := auth.HashRefreshToken(refreshToken)
+	stored, err := qtx.GetRefreshTokenForUpdate(r.Context(), tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", nil)
+			return
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve refresh token", err)
+		return
+	}
+	userID := uuid.NullUUID{UUID: stored.UserID, Valid: true}
+
+	if stored.RotatedAt.Valid {
+		err = qtx.RevokeRefreshTokenFamily(r.Context(), stored.FamilyID)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't revoke refresh tokens", err)
+			return
+		}
+		err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+			Action:   audit.ActionTokenReused,
+			ActorID:  userID,
+			TargetID: userID,
+			Details:  map[string]any{"family_id": stored.FamilyID},
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+			return
+		}
+		response.WithError(w, http.StatusUnauthorized, "Refresh token has already been used", nil)
+		return
+	}
+	if stored.RevokedAt.Valid || !stored.ExpiresAt.After(time.Now()) {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", nil)
+		return
+	}
+
+	if cfg.refuseSuspended(w, r, stored.UserID) {
+		return
+	}
+	user, err := qtx.GetUserByID(r.Context(), stored.UserID)
 	if err != nil {
-		response.WithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.config.Secret, time.Hour)
+	err = qtx.RotateRefreshToken(r.Context(), tokenHash)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't rotate refresh token", err)
+		return
+	}
+	newRefreshToken, err := issueRefreshToken(r.Context(), qtx, user.ID, stored.FamilyID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
+		return
+	}
+	err = qtx.TouchSession(r.Context(), database.TouchSessionParams{
+		Ip:        audit.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		ID:        stored.FamilyID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update session", err)
+		return
+	}
+	accessToken, err := auth.MakeJWT(user.ID, auth.Role(user.Role), cfg.config.Secret, time.Hour)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Error generating JWT accessToken", err)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionTokenRefreshed,
+		ActorID:  userID,
+		TargetID: userID,
+		Details:  map[string]any{"family_id": stored.FamilyID},
+	})
 	if err != nil {
-		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
 		return
 	}
-	response.WithJSON(w, http.StatusOK, models.TokenResponse{Token: accessToken})
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, models.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+	})
 }
 
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
@@ -35,10 +153,22 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, http.StatusBadRequest, "Couldn't find token", err)
 		return
 	}
-	err = cfg.db.RevokeRefreshTokenByToken(r.Context(), refreshToken)
+	tokenHash := auth.HashRefreshToken(refreshToken)
+
+	// The owner is only known while the token is still valid.
+	var userID uuid.NullUUID
+	if user, err := cfg.db.GetUserFromRefreshToken(r.Context(), tokenHash); err == nil {
+		userID = uuid.NullUUID{UUID: user.ID, Valid: true}
+	}
+	err = cfg.db.RevokeRefreshTokenByToken(r.Context(), tokenHash)
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
 		return
 	}
+	cfg.audit.Log(r, audit.Event{
+		Action:   audit.ActionTokenRevoked,
+		ActorID:  userID,
+		TargetID: userID,
+	})
 	response.WithJSON(w, http.StatusNoContent, nil)
 }
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	reportStatusOpen      = "open"
+	reportStatusResolved  = "resolved"
+	reportStatusDismissed = "dismissed"
+	reportStatusRemoved   = "removed"
+
+	maxReportDetailsLength = 500
+	maxReportNoteLength    = 500
+)
+
+var reportStatuses = []string{reportStatusOpen, reportStatusResolved, reportStatusDismissed, reportStatusRemoved}
+
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "self_harm", "sexual", "misinformation", "other"}
+
+// reportActions maps the actions a moderator can take on an open report to
+// the status it is closed with.
+var reportActions = map[string]string{
+	"resolve": reportStatusResolved,
+	"dismiss": reportStatusDismissed,
+	"remove":  reportStatusRemoved,
+}
+
+func reportFromDB(report database.Report) models.Report {
+	payload := models.Report{
+		ID:             report.ID,
+		ChirpID:        uuidPtr(report.ChirpID),
+		ChirpAuthorID:  report.ChirpAuthorID,
+		ChirpBody:      report.ChirpBody,
+		ReporterID:     report.ReporterID,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		Status:         report.Status,
+		ResolvedBy:     uuidPtr(report.ResolvedBy),
+		ResolutionNote: report.ResolutionNote,
+		CreatedAt:      report.CreatedAt,
+	}
+	if report.ResolvedAt.Valid {
+		payload.ResolvedAt = &report.ResolvedAt.Time
+	}
+	return payload
+}
+
+func (cfg *apiConfig) handlerReportsCreate(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid chirpID in the url", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.CreateReportRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	if !slices.Contains(reportReasons, params.Reason) {
+		response.WithError(w, http.StatusBadRequest, "reason must be one of "+strings.Join(reportReasons, ", "), nil)
+		return
+	}
+	details := strings.TrimSpace(params.Details)
+	if len(details) > maxReportDetailsLength {
+		response.WithError(w, http.StatusBadRequest, "details are too long", nil)
+		return
+	}
+
+	chirp, ok := cfg.getVisibleChirp(w, r, uuid.NullUUID{UUID: userID, Valid: true}, chirpID)
+	if !ok {
+		return
+	}
+	if chirp.UserID == userID {
+		response.WithError(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		return
+	}
+
+	// Reporting a chirp again while the first report is open updates it.
+	report, err := cfg.db.UpsertReport(r.Context(), database.UpsertReportParams{
+		ChirpID:       uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		ChirpAuthorID: chirp.UserID,
+		ChirpBody:     chirp.Body,
+		ReporterID:    userID,
+		Reason:        params.Reason,
+		Details:       details,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't report chirp", err)
+		return
+	}
+	response.WithJSON(w, http.StatusCreated, reportFromDB(report))
+}
+
+func (cfg *apiConfig) handlerReportsGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, cursor, err := pagination.Parse(query)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	status := query.Get("status")
+	if status == "" {
+		status = reportStatusOpen
+	}
+	if !slices.Contains(reportStatuses, status) {
+		response.WithError(w, http.StatusBadRequest, "status must be one of "+strings.Join(reportStatuses, ", "), nil)
+		return
+	}
+
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	reports, err := cfg.db.ListReports(r.Context(), database.ListReportsParams{
+		Status:          status,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve reports", err)
+		return
+	}
+
+	// The queue is oldest first so reports are handled in the order they came in.
+	page := models.ReportPage{Reports: []models.Report{}}
+	if len(reports) > int(limit) {
+		reports = reports[:limit]
+		last := reports[len(reports)-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, report := range reports {
+		page.Reports = append(page.Reports, reportFromDB(report))
+	}
+	response.WithJSON(w, http.StatusOK, page)
+}
+
+func (cfg *apiConfig) handlerReportAction(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid reportID in the url", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.ReportActionRequest{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	status, ok := reportActions[params.Action]
+	if !ok {
+		response.WithError(w, http.StatusBadRequest, "action must be one of resolve, dismiss, remove", nil)
+		return
+	}
+	note := strings.TrimSpace(params.Note)
+	if note == "" {
+		response.WithError(w, http.StatusBadRequest, "A note explaining the decision is required", nil)
+		return
+	}
+	if len(note) > maxReportNoteLength {
+		response.WithError(w, http.StatusBadRequest, "note is too long", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.GetReportForUpdate(r.Context(), reportID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			response.WithError(w, http.StatusNotFound, "report not found", nil)
+			return
+		}
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve report", err)
+		return
+	}
+	if report.Status != reportStatusOpen {
+		response.WithError(w, http.StatusConflict, "Report has already been closed", nil)
+		return
+	}
+	if status == reportStatusRemoved && !report.ChirpID.Valid {
+		response.WithError(w, http.StatusConflict, "Reported chirp no longer exists", nil)
+		return
+	}
+
+	resolvedBy := uuid.NullUUID{UUID: claims.UserID, Valid: true}
+	report, err = qtx.ResolveReport(r.Context(), database.ResolveReportParams{
+		Status:         status,
+		ResolvedBy:     resolvedBy,
+		ResolutionNote: note,
+		ID:             reportID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update report", err)
+		return
+	}
+
+	if status == reportStatusRemoved {
+		// Other open reports about the chirp are settled by its removal.
+		err = qtx.ResolveChirpReports(r.Context(), database.ResolveChirpReportsParams{
+			Status:         status,
+			ResolvedBy:     resolvedBy,
+			ResolutionNote: note,
+			ChirpID:        report.ChirpID,
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't update reports", err)
+			return
+		}
+		if err := deleteChirp(r.Context(), qtx, report.ChirpID.UUID); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't delete the chirp instance from db", err)
+			return
+		}
+	}
+
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionReportClosed,
+		ActorID:  resolvedBy,
+		TargetID: uuid.NullUUID{UUID: report.ID, Valid: true},
+		Details: map[string]any{
+			"action":          params.Action,
+			"note":            note,
+			"chirp_id":        report.ChirpID,
+			"chirp_author_id": report.ChirpAuthorID,
+		},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, reportFromDB(report))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+// startSession creates a session for userID on the device r came from and
+// returns its first refresh token.
+func (cfg *apiConfig) startSession(r *http.Request, userID uuid.UUID) (string, error) {
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	session, err := qtx.CreateSession(r.Context(), database.CreateSessionParams{
+		UserID:    userID,
+		Ip:        audit.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		return "", err
+	}
+	refreshToken, err := issueRefreshToken(r.Context(), qtx, userID, session.ID)
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, tx.Commit()
+}
+
+func (cfg *apiConfig) handlerSessionsGet(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	sessions, err := cfg.db.ListActiveSessions(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve sessions", err)
+		return
+	}
+	payload := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		payload = append(payload, models.Session{
+			ID:         session.ID,
+			IP:         session.Ip,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+		})
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerSessionDelete(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "Invalid sessionID in the url", err)
+		return
+	}
+
+	revoked, err := cfg.db.RevokeSession(r.Context(), database.RevokeSessionParams{
+		FamilyID: sessionID,
+		UserID:   userID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+	if revoked == 0 {
+		response.WithError(w, http.StatusNotFound, "session not found", nil)
+		return
+	}
+	cfg.audit.Log(r, audit.Event{
+		Action:   audit.ActionSessionRevoked,
+		ActorID:  uuid.NullUUID{UUID: userID, Valid: true},
+		TargetID: uuid.NullUUID{UUID: userID, Valid: true},
+		Details:  map[string]any{"session_id": sessionID},
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerSessionsRevokeAll signs the caller out everywhere. Access tokens
+// that were already handed out keep working until they expire.
+func (cfg *apiConfig) handlerSessionsRevokeAll(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	err = cfg.db.RevokeUserRefreshTokens(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+	cfg.audit.Log(r, audit.Event{
+		Action:   audit.ActionSessionsRevoked,
+		ActorID:  uuid.NullUUID{UUID: userID, Valid: true},
+		TargetID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/totp"
+)
+
+const (
+	totpIssuer            = "Chirpy"
+	twoFactorChallengeTTL = 5 * time.Minute
+	recoveryCodeCount     = 10
+
+	// After maxTwoFactorFailures wrong codes in a row, codes are refused
+	// until twoFactorLockout has passed since the last one.
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+const (
+	secondFactorTOTP         = "totp"
+	secondFactorRecoveryCode = "recovery_code"
+)
+
+func (cfg *apiConfig) twoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	userTOTP, err := cfg.db.GetUserTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return userTOTP.ConfirmedAt.Valid, nil
+}
+
+func twoFactorLocked(userTOTP database.UserTotp) bool {
+	return userTOTP.FailedAttempts >= maxTwoFactorFailures &&
+		userTOTP.LastFailedAt.Valid &&
+		time.Now().UTC().Sub(userTOTP.LastFailedAt.Time) < twoFactorLockout
+}
+
+// checkSecondFactor consumes code if it is a valid TOTP code or, when
+// allowRecovery is set, an unused recovery code, and returns which of the
+// two it was. It returns an empty string and counts a failure otherwise.
+// userTOTP must have been locked with GetUserTOTPForUpdate through q, and the
+// transaction committed even when the code is wrong so the failure sticks.
+func (cfg *apiConfig) checkSecondFactor(ctx context.Context, q *database.Queries, userTOTP database.UserTotp, code string, allowRecovery bool) (string, error) {
+	secret, err := cfg.totpBox.Open(userTOTP.SecretCiphertext, userTOTP.UserID[:])
+	if err != nil {
+		return "", err
+	}
+
+	method := ""
+	step := userTOTP.LastUsedStep
+	if matched, ok, err := totp.Validate(string(secret), code, time.Now().UTC(), userTOTP.LastUsedStep); err != nil {
+		return "", err
+	} else if ok {
+		method, step = secondFactorTOTP, matched
+	} else if allowRecovery {
+		used, err := q.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+			UserID:   userTOTP.UserID,
+			CodeHash: auth.HashToken(totp.NormalizeRecoveryCode(code)),
+		})
+		if err != nil {
+			return "", err
+		}
+		if used > 0 {
+			method = secondFactorRecoveryCode
+		}
+	}
+
+	if method == "" {
+		return "", q.RecordTOTPFailure(ctx, userTOTP.UserID)
+	}
+	err = q.RecordTOTPSuccess(ctx, database.RecordTOTPSuccessParams{Step: step, UserID: userTOTP.UserID})
+	return method, err
+}
+
+// replaceRecoveryCodes invalidates the user's recovery codes and returns a
+// fresh set, which is only ever shown once.
+func replaceRecoveryCodes(ctx context.Context, q *database.Queries, userID uuid.UUID) ([]string, error) {
+	codes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(totp.NormalizeRecoveryCode(code))
+	}
+
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+	err = q.CreateRecoveryCodes(ctx, database.CreateRecoveryCodesParams{UserID: userID, CodeHashes: hashes})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// handlerTOTPEnroll starts enrollment with a new secret. It only takes
+// effect once handlerTOTPConfirm sees a code generated from it, and until
+// then calling it again replaces the secret.
+func (cfg *apiConfig) handlerTOTPEnroll(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't generate secret", err)
+		return
+	}
+	sealed, err := cfg.totpBox.Seal([]byte(secret), userID[:])
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't encrypt secret", err)
+		return
+	}
+
+	_, err = cfg.db.StartTOTPEnrollment(r.Context(), database.StartTOTPEnrollmentParams{
+		UserID:           userID,
+		SecretCiphertext: sealed,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		response.WithError(w, http.StatusConflict, "Two-factor authentication is already enabled", err)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't save secret", err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, models.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer, user.Email, secret),
+	})
+}
+
+func (cfg *apiConfig) handlerTOTPConfirm(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	var params models.TwoFactorCodeRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	userTOTP, err := qtx.GetUserTOTPForUpdate(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		response.WithError(w, http.StatusNotFound, "Two-factor enrollment hasn't been started", err)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve two-factor settings", err)
+		return
+	}
+	if userTOTP.ConfirmedAt.Valid {
+		response.WithError(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if twoFactorLocked(userTOTP) {
+		response.WithError(w, http.StatusTooManyRequests, "Too many invalid codes, try again later", nil)
+		return
+	}
+
+	method, err := cfg.checkSecondFactor(r.Context(), qtx, userTOTP, params.Code, false)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check code", err)
+		return
+	}
+	if method == "" {
+		if err := tx.Commit(); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+			return
+		}
+		response.WithError(w, http.StatusUnauthorized, "Invalid code", nil)
+		return
+	}
+
+	if err := qtx.ConfirmTOTP(r.Context(), userID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't enable two-factor authentication", err)
+		return
+	}
+	codes, err := replaceRecoveryCodes(r.Context(), qtx, userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create recovery codes", err)
+		return
+	}
+
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionTwoFactorEnabled,
+		ActorID:  actor,
+		TargetID: actor,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, models.RecoveryCodes{RecoveryCodes: codes})
+}
+
+func (cfg *apiConfig) handlerTOTPDisable(w http.ResponseWriter, r *http.Request) {
+	tx, qtx, userID, ok := cfg.beginSecondFactorChange(w, r)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	if err := qtx.DeleteUserTOTP(r.Context(), userID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't disable two-factor authentication", err)
+		return
+	}
+	if err := qtx.DeleteRecoveryCodes(r.Context(), userID); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't delete recovery codes", err)
+		return
+	}
+
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	err := cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionTwoFactorDisabled,
+		ActorID:  actor,
+		TargetID: actor,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerRecoveryCodesRegenerate(w http.ResponseWriter, r *http.Request) {
+	tx, qtx, userID, ok := cfg.beginSecondFactorChange(w, r)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(r.Context(), qtx, userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't create recovery codes", err)
+		return
+	}
+
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionRecoveryCodesReset,
+		ActorID:  actor,
+		TargetID: actor,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, models.RecoveryCodes{RecoveryCodes: codes})
+}
+
+// beginSecondFactorChange authenticates a change to the caller's enabled
+// two-factor settings, which needs an access token and a current code. On
+// success it returns the transaction that consumed the code for the change
+// to be made in; otherwise it has already written the response.
+func (cfg *apiConfig) beginSecondFactorChange(w http.ResponseWriter, r *http.Request) (*sql.Tx, *database.Queries, uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return nil, nil, uuid.Nil, false
+	}
+	userID, err := auth.ValidateJWT(token, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return nil, nil, uuid.Nil, false
+	}
+
+	var params models.TwoFactorCodeRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return nil, nil, uuid.Nil, false
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return nil, nil, uuid.Nil, false
+	}
+	ok := false
+	defer func() {
+		if !ok {
+			tx.Rollback()
+		}
+	}()
+	qtx := cfg.db.WithTx(tx)
+
+	userTOTP, err := qtx.GetUserTOTPForUpdate(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !userTOTP.ConfirmedAt.Valid) {
+		response.WithError(w, http.StatusNotFound, "Two-factor authentication isn't enabled", err)
+		return nil, nil, uuid.Nil, false
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve two-factor settings", err)
+		return nil, nil, uuid.Nil, false
+	}
+	if twoFactorLocked(userTOTP) {
+		response.WithError(w, http.StatusTooManyRequests, "Too many invalid codes, try again later", nil)
+		return nil, nil, uuid.Nil, false
+	}
+
+	method, err := cfg.checkSecondFactor(r.Context(), qtx, userTOTP, params.Code, true)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check code", err)
+		return nil, nil, uuid.Nil, false
+	}
+	if method == "" {
+		if err := tx.Commit(); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+			return nil, nil, uuid.Nil, false
+		}
+		response.WithError(w, http.StatusUnauthorized, "Invalid code", nil)
+		return nil, nil, uuid.Nil, false
+	}
+
+	ok = true
+	return tx, qtx, userID, true
+}
+
+func (cfg *apiConfig) handlerLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var params models.LoginTwoFactorRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	userID, err := auth.ValidateChallengeJWT(params.ChallengeToken, cfg.config.Secret)
+	if err != nil {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't validate challenge token", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// 2FA may have been disabled since the challenge was issued, in which
+	// case the user has to log in again.
+	user, err := qtx.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	userTOTP, err := qtx.GetUserTOTPForUpdate(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !userTOTP.ConfirmedAt.Valid) {
+		response.WithError(w, http.StatusBadRequest, "Two-factor authentication isn't enabled", err)
+		return
+	}
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve two-factor settings", err)
+		return
+	}
+
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	if twoFactorLocked(userTOTP) {
+		cfg.audit.Log(r, loginFailed(actor, user.Email, "2fa_locked"))
+		response.WithError(w, http.StatusTooManyRequests, "Too many invalid codes, try again later", nil)
+		return
+	}
+
+	method, err := cfg.checkSecondFactor(r.Context(), qtx, userTOTP, params.Code, true)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check code", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	if method == "" {
+		cfg.audit.Log(r, loginFailed(actor, user.Email, "invalid_2fa_code"))
+		response.WithError(w, http.StatusUnauthorized, "Invalid code", nil)
+		return
+	}
+
+	if cfg.refuseSuspended(w, r, userID) {
+		cfg.audit.Log(r, loginFailed(actor, user.Email, "suspended"))
+		return
+	}
+	cfg.completeLogin(w, r, user, map[string]any{"second_factor": method})
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+const (
+	restrictionSuspension = "suspension"
+	restrictionShadowBan  = "shadow_ban"
+
+	maxRestrictionReasonLength = 500
+)
+
+var restrictionKinds = []string{restrictionSuspension, restrictionShadowBan}
+
+func userRestrictionFromDB(restriction database.UserRestriction) models.UserRestriction {
+	payload := models.UserRestriction{
+		ID:         restriction.ID,
+		UserID:     restriction.UserID,
+		Kind:       restriction.Kind,
+		Reason:     restriction.Reason,
+		CreatedBy:  uuidPtr(restriction.CreatedBy),
+		LiftedBy:   uuidPtr(restriction.LiftedBy),
+		LiftReason: restriction.LiftReason,
+		CreatedAt:  restriction.CreatedAt,
+	}
+	if restriction.ExpiresAt.Valid {
+		payload.ExpiresAt = &restriction.ExpiresAt.Time
+	}
+	if restriction.LiftedAt.Valid {
+		payload.LiftedAt = &restriction.LiftedAt.Time
+	}
+	payload.Active = !restriction.LiftedAt.Valid && (!restriction.ExpiresAt.Valid || restriction.ExpiresAt.Time.After(time.Now()))
+	return payload
+}
+
+// suspension returns the message suspended users are refused with. It is
+// empty when userID isn't suspended.
+func (cfg *apiConfig) suspension(ctx context.Context, userID uuid.UUID) (string, error) {
+	restriction, err := cfg.db.GetActiveRestriction(ctx, database.GetActiveRestrictionParams{
+		UserID: userID,
+		Kind:   restrictionSuspension,
+	})
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	msg := "Your account is suspended"
+	if restriction.ExpiresAt.Valid {
+		msg += " until " + restriction.ExpiresAt.Time.UTC().Format(time.RFC3339)
+	}
+	return msg + ": " + restriction.Reason, nil
+}
+
+// refuseSuspended writes a 403 and reports true when userID is suspended.
+func (cfg *apiConfig) refuseSuspended(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	msg, err := cfg.suspension(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't check account status", err)
+		return true
+	}
+	if msg != "" {
+		response.WithError(w, http.StatusForbidden, msg, nil)
+		return true
+	}
+	return false
+}
+
+func parseRestrictionReason(reason string) (string, bool) {
+	reason = strings.TrimSpace(reason)
+	return reason, reason != "" && len(reason) <= maxRestrictionReasonLength
+}
+
+func (cfg *apiConfig) handlerUserRestrictionsCreate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.CreateRestrictionRequest{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	if !slices.Contains(restrictionKinds, params.Kind) {
+		response.WithError(w, http.StatusBadRequest, "kind must be one of "+strings.Join(restrictionKinds, ", "), nil)
+		return
+	}
+	reason, ok := parseRestrictionReason(params.Reason)
+	if !ok {
+		response.WithError(w, http.StatusBadRequest, "A reason of at most 500 characters is required", nil)
+		return
+	}
+	var expiresAt sql.NullTime
+	if params.ExpiresAt != nil {
+		if !params.ExpiresAt.After(time.Now()) {
+			response.WithError(w, http.StatusBadRequest, "expires_at must be in the future", nil)
+			return
+		}
+		expiresAt = sql.NullTime{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	if user.ID == claims.UserID {
+		response.WithError(w, http.StatusBadRequest, "You can't restrict yourself", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	restriction, err := qtx.CreateUserRestriction(r.Context(), database.CreateUserRestrictionParams{
+		UserID:    user.ID,
+		Kind:      params.Kind,
+		Reason:    reason,
+		CreatedBy: uuid.NullUUID{UUID: claims.UserID, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't restrict user", err)
+		return
+	}
+	// Suspended users are logged out everywhere once their access tokens
+	// expire, they can't get new ones.
+	if params.Kind == restrictionSuspension {
+		if err := qtx.RevokeUserRefreshTokens(r.Context(), user.ID); err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+			return
+		}
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionUserRestricted,
+		ActorID:  restriction.CreatedBy,
+		TargetID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Details: map[string]any{
+			"restriction_id": restriction.ID,
+			"kind":           restriction.Kind,
+			"reason":         restriction.Reason,
+			"expires_at":     params.ExpiresAt,
+		},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusCreated, userRestrictionFromDB(restriction))
+}
+
+func (cfg *apiConfig) handlerUserRestrictionsGet(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+
+	restrictions, err := cfg.db.ListUserRestrictions(r.Context(), user.ID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve restrictions", err)
+		return
+	}
+	payload := make([]models.UserRestriction, 0, len(restrictions))
+	for _, restriction := range restrictions {
+		payload = append(payload, userRestrictionFromDB(restriction))
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
+
+func (cfg *apiConfig) handlerUserRestrictionsLift(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.LiftRestrictionRequest{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	if !slices.Contains(restrictionKinds, params.Kind) {
+		response.WithError(w, http.StatusBadRequest, "kind must be one of "+strings.Join(restrictionKinds, ", "), nil)
+		return
+	}
+	reason, ok := parseRestrictionReason(params.Reason)
+	if !ok {
+		response.WithError(w, http.StatusBadRequest, "A reason of at most 500 characters is required", nil)
+		return
+	}
+
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	liftedBy := uuid.NullUUID{UUID: claims.UserID, Valid: true}
+	lifted, err := qtx.LiftUserRestrictions(r.Context(), database.LiftUserRestrictionsParams{
+		LiftedBy:   liftedBy,
+		LiftReason: reason,
+		UserID:     user.ID,
+		Kind:       params.Kind,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't lift restriction", err)
+		return
+	}
+	if len(lifted) == 0 {
+		response.WithError(w, http.StatusNotFound, "User has no active "+params.Kind, nil)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionRestrictionLifted,
+		ActorID:  liftedBy,
+		TargetID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Details:  map[string]any{"kind": params.Kind, "reason": reason},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	payload := make([]models.UserRestriction, 0, len(lifted))
+	for _, restriction := range lifted {
+		payload = append(payload, userRestrictionFromDB(restriction))
+	}
+	response.WithJSON(w, http.StatusOK, payload)
+}
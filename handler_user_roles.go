@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/response"
+)
+
+// currentRole returns the role userID has now, which may differ from the one
+// in their access token.
+func (cfg *apiConfig) currentRole(ctx context.Context, userID uuid.UUID) (auth.Role, error) {
+	user, err := cfg.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return auth.ParseRole(user.Role)
+}
+
+func (cfg *apiConfig) handlerUserRoleUpdate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := models.UpdateRoleRequest{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't decode params", err)
+		return
+	}
+	role, err := auth.ParseRole(params.Role)
+	if err != nil {
+		response.WithError(w, http.StatusBadRequest, "role must be one of user, moderator, admin", err)
+		return
+	}
+
+	user, ok := cfg.getPathUser(w, r)
+	if !ok {
+		return
+	}
+	// Admins can't demote themselves so there is always someone left to
+	// manage roles.
+	if user.ID == claims.UserID && role != auth.RoleAdmin {
+		response.WithError(w, http.StatusBadRequest, "You can't change your own role", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	oldRole := user.Role
+	user, err = qtx.UpdateUserRole(r.Context(), database.UpdateUserRoleParams{
+		Role: string(role),
+		ID:   user.ID,
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't update role", err)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionRoleChanged,
+		ActorID:  uuid.NullUUID{UUID: claims.UserID, Valid: true},
+		TargetID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Details:  map[string]any{"old_role": oldRole, "new_role": user.Role},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, userFromDB(user))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/mailer"
 	"github.com/markoc1120/go_server/internal/models"
 	"github.com/markoc1120/go_server/internal/response"
 	"github.com/markoc1120/go_server/internal/validation"
@@ -37,7 +38,15 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	user, err := cfg.db.CreateUser(
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.CreateUser(
 		r.Context(),
 		database.CreateUserParams{Email: params.Email, HashedPassword: passwordHash},
 	)
@@ -46,11 +55,33 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response.WithJSON(w, http.StatusCreated, models.User{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
-	})
+	var msg mailer.Message
+	if cfg.mailer != nil {
+		msg, err = cfg.startEmailVerification(r, qtx, user.ID, user.Email)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+	if cfg.mailer != nil {
+		cfg.sendEmail(msg)
+	}
+	response.WithJSON(w, http.StatusCreated, userFromDB(user))
+}
+
+func userFromDB(user database.User) models.User {
+	return models.User{
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		IsChirpyRed:   user.IsChirpyRed,
+		Role:          user.Role,
+	}
 }
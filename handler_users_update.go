@@ -1,11 +1,17 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
 	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/mailer"
 	"github.com/markoc1120/go_server/internal/models"
 	"github.com/markoc1120/go_server/internal/response"
 	"github.com/markoc1120/go_server/internal/validation"
@@ -46,21 +52,100 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	user, err := cfg.db.UpdateUser(r.Context(), database.UpdateUserParams{
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	oldUser, err := qtx.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	user, err := qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
 		HashedPassword: passwordHash,
 		ID:             userID,
-		Email:          params.Email,
 	})
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't update password", err)
 		return
 	}
 
-	response.WithJSON(w, http.StatusOK, models.User{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
+	actor := uuid.NullUUID{UUID: userID, Valid: true}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:   audit.ActionPasswordChanged,
+		ActorID:  actor,
+		TargetID: actor,
 	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	// A new email only replaces the old one once its owner follows the link
+	// sent to it, see handlerEmailVerify.
+	var msg *mailer.Message
+	if params.Email != oldUser.Email {
+		if cfg.mailer == nil {
+			response.WithError(w, http.StatusServiceUnavailable, "Email changes are disabled", nil)
+			return
+		}
+		existing, err := qtx.GetUser(r.Context(), params.Email)
+		if err == nil && existing.ID != userID {
+			response.WithError(w, http.StatusConflict, "Email already in use", nil)
+			return
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't check email", err)
+			return
+		}
+
+		recent, err := qtx.CountRecentEmailVerificationTokens(r.Context(), database.CountRecentEmailVerificationTokensParams{
+			UserID:    userID,
+			CreatedAt: time.Now().UTC().Add(-emailVerificationWindow),
+		})
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't check verification tokens", err)
+			return
+		}
+		if recent >= maxEmailVerificationTokens {
+			response.WithError(w, http.StatusTooManyRequests, "Too many verification emails, try again later", nil)
+			return
+		}
+
+		verification, err := cfg.startEmailVerification(r, qtx, userID, params.Email)
+		if err != nil {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+			return
+		}
+		msg = &verification
+	} else {
+		// Changing back to the current address cancels a pending change.
+		pending, err := qtx.GetPendingEmail(r.Context(), userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.WithError(w, http.StatusInternalServerError, "Couldn't retrieve pending email", err)
+			return
+		}
+		if err == nil && pending != oldUser.Email {
+			if err := qtx.UseEmailVerificationTokens(r.Context(), userID); err != nil {
+				response.WithError(w, http.StatusInternalServerError, "Couldn't invalidate verification tokens", err)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
+
+	resp := models.UpdatedUser{User: userFromDB(user)}
+	if msg != nil {
+		cfg.sendEmail(*msg)
+		resp.PendingEmail = msg.To
+	}
+	response.WithJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/notifications"
+	"github.com/markoc1120/go_server/internal/response"
+	"github.com/markoc1120/go_server/internal/websocket"
+)
+
+const (
+	wsMaxMessageSize = 4096
+	wsSendBufferSize = 64
+	wsAuthWait       = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingEvery      = 30 * time.Second
+	wsWriteWait      = 10 * time.Second
+)
+
+const (
+	wsChannelChirpsPrefix = "chirps:"
+	wsChannelMentions     = "mentions"
+	wsChannelNotification = "notifications"
+	wsChannelMessages     = "messages"
+)
+
+var errUnknownChannel = errors.New("unknown channel")
+
+func mentionsTopic(userID uuid.UUID) string {
+	return "mentions:" + userID.String()
+}
+
+// wsChannels tracks the channels a connection is subscribed to, keyed by the
+// pubsub topic that feeds them.
+type wsChannels struct {
+	mu     sync.RWMutex
+	topics map[string]string
+}
+
+func (c *wsChannels) add(topic, channel string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[topic] = channel
+}
+
+func (c *wsChannels) remove(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.topics, topic)
+}
+
+func (c *wsChannels) channel(topic string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	channel, ok := c.topics[topic]
+	return channel, ok
+}
+
+// wsTopic resolves a channel name requested by a client to a pubsub topic.
+// Mentions, notifications and messages are only ever available for the
+// caller.
+func wsTopic(channel string, userID uuid.UUID) (string, error) {
+	switch channel {
+	case wsChannelMentions:
+		return mentionsTopic(userID), nil
+	case wsChannelNotification:
+		return notifications.Topic(userID), nil
+	case wsChannelMessages:
+		return messagesTopic(userID), nil
+	}
+	if id, found := strings.CutPrefix(channel, wsChannelChirpsPrefix); found {
+		authorID, err := uuid.Parse(id)
+		if err != nil {
+			return "", errUnknownChannel
+		}
+		return chirpsTopic(authorID), nil
+	}
+	return "", errUnknownChannel
+}
+
+func (cfg *apiConfig) handlerWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, wsMaxMessageSize)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			response.WithError(w, http.StatusBadRequest, "Expected a websocket handshake", err)
+			return
+		}
+		log.Printf("Error upgrading websocket connection: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	userID, err := cfg.authenticateWebSocket(r, conn)
+	if err != nil {
+		writeWS(conn, models.WSServerMessage{Type: "error", Error: "Couldn't validate token"})
+		conn.WriteClose(websocket.ClosePolicyViolation, "unauthorized", time.Now().Add(wsWriteWait))
+		return
+	}
+	if err := writeWS(conn, models.WSServerMessage{Type: "ready"}); err != nil {
+		return
+	}
+
+	channels := &wsChannels{topics: map[string]string{}}
+	sub, _ := cfg.events.Subscribe(0, wsSendBufferSize, func(topic string) bool {
+		_, ok := channels.channel(topic)
+		return ok
+	})
+	defer sub.Close()
+
+	replies := make(chan models.WSServerMessage, 16)
+	readerDone := make(chan struct{})
+	writerDone := make(chan struct{})
+	defer close(writerDone)
+	go func() {
+		defer close(readerDone)
+		cfg.readWS(r.Context(), conn, userID, channels, replies, writerDone)
+	}()
+
+	ping := time.NewTicker(wsPingEvery)
+	defer ping.Stop()
+
+	viewer := uuid.NullUUID{UUID: userID, Valid: true}
+
+	for {
+		var err error
+		select {
+		case <-readerDone:
+			return
+		case <-sub.Done():
+			conn.WriteClose(websocket.CloseTryAgainLater, "slow consumer", time.Now().Add(wsWriteWait))
+			return
+		case event := <-sub.Events():
+			channel, ok := channels.channel(event.Topic)
+			if !ok {
+				continue
+			}
+			hidden, hiddenErr := cfg.chirpEventHidden(r.Context(), viewer, event)
+			if hiddenErr != nil {
+				log.Printf("Error checking visibility for websocket event: %s", hiddenErr)
+				continue
+			}
+			if hidden {
+				continue
+			}
+			err = writeWS(conn, models.WSServerMessage{
+				Type:    "event",
+				Channel: channel,
+				ID:      event.ID,
+				Data:    event.Data,
+			})
+		case reply := <-replies:
+			err = writeWS(conn, reply)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.OpPing, nil, time.Now().Add(wsWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// authenticateWebSocket accepts the access token either as a bearer token on
+// the handshake or, for browsers that can't set headers, as the first message.
+func (cfg *apiConfig) authenticateWebSocket(r *http.Request, conn *websocket.Conn) (uuid.UUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		conn.SetReadDeadline(time.Now().Add(wsAuthWait))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return uuid.Nil, err
+		}
+		var msg models.WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return uuid.Nil, err
+		}
+		if msg.Type != "auth" {
+			return uuid.Nil, errors.New("expected an auth message")
+		}
+		token = msg.Token
+	}
+	return auth.ValidateJWT(token, cfg.config.Secret)
+}
+
+// readWS handles client messages until the connection fails. Replies go to
+// the writer loop, and once it has exited and closed done the reader gives
+// up instead of blocking on a channel nobody drains.
+func (cfg *apiConfig) readWS(ctx context.Context, conn *websocket.Conn, userID uuid.UUID, channels *wsChannels, replies chan<- models.WSServerMessage, done <-chan struct{}) {
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func() {
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		select {
+		case replies <- cfg.handleWSMessage(ctx, data, userID, channels):
+		case <-done:
+			return
+		}
+	}
+}
+
+// handleWSMessage applies a client message and returns the reply to it.
+func (cfg *apiConfig) handleWSMessage(ctx context.Context, data []byte, userID uuid.UUID, channels *wsChannels) models.WSServerMessage {
+	var msg models.WSClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return models.WSServerMessage{Type: "error", Error: "Couldn't decode message"}
+	}
+
+	switch msg.Type {
+	case "subscribe", "unsubscribe":
+		topic, err := wsTopic(msg.Channel, userID)
+		if err != nil {
+			return models.WSServerMessage{Type: "error", Channel: msg.Channel, Error: err.Error()}
+		}
+		if msg.Type == "unsubscribe" {
+			channels.remove(topic)
+			return models.WSServerMessage{Type: "unsubscribed", Channel: msg.Channel}
+		}
+		blocked, err := cfg.chirpsTopicBlocked(ctx, uuid.NullUUID{UUID: userID, Valid: true}, topic)
+		if err != nil {
+			return models.WSServerMessage{Type: "error", Channel: msg.Channel, Error: "Couldn't check blocks"}
+		}
+		if blocked {
+			return models.WSServerMessage{Type: "error", Channel: msg.Channel, Error: errUnknownChannel.Error()}
+		}
+		channels.add(topic, msg.Channel)
+		return models.WSServerMessage{Type: "subscribed", Channel: msg.Channel}
+	case "ping":
+		return models.WSServerMessage{Type: "pong"}
+	default:
+		return models.WSServerMessage{Type: "error", Error: "unknown message type"}
+	}
+}
+
+func writeWS(conn *websocket.Conn, msg models.WSServerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.OpText, data, time.Now().Add(wsWriteWait))
+}
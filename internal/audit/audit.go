@@ -0,0 +1,121 @@
+// Package audit records security relevant and administrative actions in the
+// append-only audit_events table.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/models"
+)
+
+const (
+	ActionLoginSucceeded     = "login.succeeded"
+	ActionLoginFailed        = "login.failed"
+	ActionEmailChanged       = "user.email_changed"
+	ActionEmailVerifySent    = "user.email_verification_requested"
+	ActionEmailVerified      = "user.email_verified"
+	ActionPasswordChanged    = "user.password_changed"
+	ActionPasswordResetSent  = "user.password_reset_requested"
+	ActionPasswordReset      = "user.password_reset"
+	ActionTwoFactorEnabled   = "user.2fa_enabled"
+	ActionTwoFactorDisabled  = "user.2fa_disabled"
+	ActionRecoveryCodesReset = "user.recovery_codes_regenerated"
+	ActionTokenRefreshed     = "token.refreshed"
+	ActionTokenRevoked       = "token.revoked"
+	ActionTokenReused        = "token.reuse_detected"
+	ActionSessionRevoked     = "session.revoked"
+	ActionSessionsRevoked    = "session.revoked_all"
+	ActionUserUpgraded       = "user.upgraded"
+	ActionRoleChanged        = "user.role_changed"
+	ActionUserRestricted     = "user.restricted"
+	ActionRestrictionLifted  = "user.restriction_lifted"
+	ActionDataReset          = "data.reset"
+	ActionProfaneWordAdded   = "moderation.word_added"
+	ActionProfaneWordRemoved = "moderation.word_removed"
+	ActionReportClosed       = "moderation.report_closed"
+)
+
+// Event is an action to record. ActorID is the user who did it, it is empty
+// for anonymous requests and webhooks. TargetID is what the action was done
+// to, such as a user or a report.
+type Event struct {
+	Action   string
+	ActorID  uuid.NullUUID
+	TargetID uuid.NullUUID
+	Details  map[string]any
+}
+
+type Logger struct {
+	db *database.Queries
+}
+
+func NewLogger(db *database.Queries) *Logger {
+	return &Logger{db: db}
+}
+
+// Record stores e using q so that it commits or rolls back together with the
+// caller's transaction. The client's IP, user agent and request ID are taken
+// from r.
+func (l *Logger) Record(ctx context.Context, q *database.Queries, r *http.Request, e Event) error {
+	details := []byte("{}")
+	if len(e.Details) > 0 {
+		var err error
+		details, err = json.Marshal(e.Details)
+		if err != nil {
+			return err
+		}
+	}
+	return q.CreateAuditEvent(ctx, database.CreateAuditEventParams{
+		Action:    e.Action,
+		ActorID:   e.ActorID,
+		TargetID:  e.TargetID,
+		Ip:        ClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: middleware.RequestIDFrom(r.Context()),
+		Details:   details,
+	})
+}
+
+// Log records e outside of any transaction. Failures are logged rather than
+// returned so they don't change the outcome of the request being audited.
+func (l *Logger) Log(r *http.Request, e Event) {
+	if err := l.Record(r.Context(), l.db, r, e); err != nil {
+		log.Printf("Couldn't record audit event %s: %s", e.Action, err)
+	}
+}
+
+// ClientIP returns the address the request came from. Forwarding headers are
+// ignored since any client can set them.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func FromDB(event database.AuditEvent) models.AuditEvent {
+	payload := models.AuditEvent{
+		ID:        event.ID,
+		Action:    event.Action,
+		IP:        event.Ip,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt,
+	}
+	if event.ActorID.Valid {
+		payload.ActorID = &event.ActorID.UUID
+	}
+	if event.TargetID.Valid {
+		payload.TargetID = &event.TargetID.UUID
+	}
+	return payload
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "ipv4", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "ipv6", remoteAddr: "[2001:db8::1]:443", want: "2001:db8::1"},
+		{name: "no port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+		{name: "forwarded header ignored", remoteAddr: "10.0.0.1:80", forwarded: "198.51.100.1", want: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromDB(t *testing.T) {
+	actorID := uuid.New()
+	event := database.AuditEvent{
+		ID:        uuid.New(),
+		Action:    ActionLoginFailed,
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+		Ip:        "203.0.113.7",
+		Details:   json.RawMessage(`{"reason":"wrong_password"}`),
+		CreatedAt: time.Now(),
+	}
+
+	got := FromDB(event)
+	if got.ActorID == nil || *got.ActorID != actorID {
+		t.Errorf("ActorID = %v, want %v", got.ActorID, actorID)
+	}
+	if got.TargetID != nil {
+		t.Errorf("TargetID = %v, want nil", got.TargetID)
+	}
+	if string(got.Details) != `{"reason":"wrong_password"}` {
+		t.Errorf("Details = %s", got.Details)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -17,7 +18,8 @@ import (
 type TokenType string
 
 const (
-	TokenTypeAccess TokenType = "chirpy-access"
+	TokenTypeAccess    TokenType = "chirpy-access"
+	TokenTypeChallenge TokenType = "chirpy-2fa-challenge"
 )
 
 const (
@@ -44,15 +46,29 @@ func CheckPasswordHash(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+// AccessClaims are the claims carried by an access token.
+type AccessClaims struct {
+	UserID uuid.UUID
+	Role   Role
+}
+
+type accessTokenClaims struct {
+	Role Role `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func MakeJWT(userID uuid.UUID, role Role, tokenSecret string, expiresIn time.Duration) (string, error) {
 	currTime := time.Now().UTC()
 	token := jwt.NewWithClaims(
 		jwt.SigningMethodHS256,
-		jwt.RegisteredClaims{
-			Issuer:    string(TokenTypeAccess),
-			IssuedAt:  jwt.NewNumericDate(currTime),
-			ExpiresAt: jwt.NewNumericDate(currTime.Add(expiresIn)),
-			Subject:   userID.String(),
+		accessTokenClaims{
+			Role: role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    string(TokenTypeAccess),
+				IssuedAt:  jwt.NewNumericDate(currTime),
+				ExpiresAt: jwt.NewNumericDate(currTime.Add(expiresIn)),
+				Subject:   userID.String(),
+			},
 		},
 	)
 	return token.SignedString([]byte(tokenSecret))
@@ -60,7 +76,17 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 
 // TODO: write more tests for ValidateJWT, create errors which can be tested in the auth_test.go
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	claimStruct := jwt.RegisteredClaims{}
+	claims, err := ValidateAccessToken(tokenString, tokenSecret)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// ValidateAccessToken validates an access token and returns its claims.
+// Tokens issued before roles existed carry no role and belong to plain users.
+func ValidateAccessToken(tokenString, tokenSecret string) (AccessClaims, error) {
+	claimStruct := accessTokenClaims{}
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&claimStruct,
@@ -70,20 +96,70 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 	)
 
 	if err != nil {
-		return uuid.Nil, err
+		return AccessClaims{}, err
 	}
 
 	userIDString, err := token.Claims.GetSubject()
 	if err != nil {
-		return uuid.Nil, err
+		return AccessClaims{}, err
 	}
 
 	issuer, err := token.Claims.GetIssuer()
 	if err != nil {
-		return uuid.Nil, err
+		return AccessClaims{}, err
 	}
 	if issuer != string(TokenTypeAccess) {
-		return uuid.Nil, errors.New("Invalid issuer")
+		return AccessClaims{}, errors.New("Invalid issuer")
+	}
+	id, err := uuid.Parse(userIDString)
+	if err != nil {
+		return AccessClaims{}, fmt.Errorf("Invalid user ID: %w", err)
+	}
+
+	role := RoleUser
+	if claimStruct.Role != "" {
+		role, err = ParseRole(string(claimStruct.Role))
+		if err != nil {
+			return AccessClaims{}, err
+		}
+	}
+	return AccessClaims{UserID: id, Role: role}, nil
+}
+
+// MakeChallengeJWT returns a token proving userID got the password right,
+// to be exchanged for an access token together with a second factor. It is
+// not accepted as an access token.
+func MakeChallengeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+	currTime := time.Now().UTC()
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodHS256,
+		jwt.RegisteredClaims{
+			Issuer:    string(TokenTypeChallenge),
+			IssuedAt:  jwt.NewNumericDate(currTime),
+			ExpiresAt: jwt.NewNumericDate(currTime.Add(expiresIn)),
+			Subject:   userID.String(),
+		},
+	)
+	return token.SignedString([]byte(tokenSecret))
+}
+
+func ValidateChallengeJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&jwt.RegisteredClaims{},
+		func(t *jwt.Token) (any, error) {
+			return []byte(tokenSecret), nil
+		},
+		jwt.WithIssuer(string(TokenTypeChallenge)),
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	userIDString, err := token.Claims.GetSubject()
+	if err != nil {
+		return uuid.Nil, err
 	}
 	id, err := uuid.Parse(userIDString)
 	if err != nil {
@@ -116,6 +192,12 @@ func GetAPIKey(headers http.Header) (string, error) {
 }
 
 func MakeRefreshToken() (string, error) {
+	return MakeToken()
+}
+
+// MakeToken returns a random token for links and sessions, such as password
+// reset tokens.
+func MakeToken() (string, error) {
 	key := make([]byte, 32)
 	_, err := rand.Read(key)
 	if err != nil {
@@ -123,3 +205,15 @@ func MakeRefreshToken() (string, error) {
 	}
 	return hex.EncodeToString(key), nil
 }
+
+// HashRefreshToken returns the form refresh tokens are stored in.
+func HashRefreshToken(token string) string {
+	return HashToken(token)
+}
+
+// HashToken returns the form tokens from MakeToken are stored in. They are
+// long and random, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -108,7 +108,7 @@ func TestJWTValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			token, err := MakeJWT(tt.userID, tt.secretToken, tt.expiresIn)
+			token, err := MakeJWT(tt.userID, RoleUser, tt.secretToken, tt.expiresIn)
 			if (err != nil) != tt.wantMakeErr {
 				t.Errorf("MakeJWT() error = %v, wantErr %v", err, tt.wantMakeErr)
 			}
@@ -121,6 +121,119 @@ func TestJWTValidation(t *testing.T) {
 	}
 }
 
+func TestAccessTokenRole(t *testing.T) {
+	const secret = "secret"
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		role Role
+		want Role
+	}{
+		{name: "user", role: RoleUser, want: RoleUser},
+		{name: "moderator", role: RoleModerator, want: RoleModerator},
+		{name: "admin", role: RoleAdmin, want: RoleAdmin},
+		{name: "no role claim", role: "", want: RoleUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := MakeJWT(userID, tt.role, secret, time.Hour)
+			if err != nil {
+				t.Fatalf("MakeJWT() error = %v", err)
+			}
+			claims, err := ValidateAccessToken(token, secret)
+			if err != nil {
+				t.Fatalf("ValidateAccessToken() error = %v", err)
+			}
+			assertEqual(t, claims.UserID, userID)
+			assertEqual(t, claims.Role, tt.want)
+		})
+	}
+
+	token, err := MakeJWT(userID, Role("root"), secret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	if _, err := ValidateAccessToken(token, secret); err == nil {
+		t.Error("expected unknown role to be rejected")
+	}
+}
+
+func TestChallengeJWT(t *testing.T) {
+	const secret = "secret"
+	userID := uuid.New()
+
+	challenge, err := MakeChallengeJWT(userID, secret, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeChallengeJWT() error = %v", err)
+	}
+	expired, err := MakeChallengeJWT(userID, secret, -time.Minute)
+	if err != nil {
+		t.Fatalf("MakeChallengeJWT() error = %v", err)
+	}
+	access, err := MakeJWT(userID, RoleUser, secret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		wantErr bool
+	}{
+		{name: "valid", token: challenge, secret: secret},
+		{name: "wrong secret", token: challenge, secret: "other", wantErr: true},
+		{name: "expired", token: expired, secret: secret, wantErr: true},
+		{name: "access token", token: access, secret: secret, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateChallengeJWT(tt.token, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateChallengeJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assertEqual(t, got, userID)
+			}
+		})
+	}
+
+	if _, err := ValidateAccessToken(challenge, secret); err == nil {
+		t.Error("expected challenge token to be rejected as an access token")
+	}
+}
+
+func TestRoleCan(t *testing.T) {
+	tests := []struct {
+		role       Role
+		permission Permission
+		want       bool
+	}{
+		{RoleUser, PermissionViewMetrics, false},
+		{RoleUser, PermissionReviewReports, false},
+		{RoleModerator, PermissionReviewReports, true},
+		{RoleModerator, PermissionManageWords, true},
+		{RoleModerator, PermissionResetData, false},
+		{RoleModerator, PermissionManageRoles, false},
+		{RoleAdmin, PermissionResetData, true},
+		{RoleAdmin, PermissionManageRoles, true},
+		{RoleModerator, PermissionRestrictUsers, false},
+		{RoleAdmin, PermissionRestrictUsers, true},
+		{RoleModerator, PermissionViewAudit, false},
+		{RoleAdmin, PermissionViewAudit, true},
+		{Role("root"), PermissionViewMetrics, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.permission), func(t *testing.T) {
+			assertEqual(t, tt.role.Can(tt.permission), tt.want)
+		})
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -163,6 +276,27 @@ func TestGetBearerToken(t *testing.T) {
 	}
 }
 
+func TestHashRefreshToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken() error = %v", err)
+	}
+	other, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken() error = %v", err)
+	}
+
+	hash := HashRefreshToken(token)
+	assertEqual(t, len(hash), 64)
+	assertEqual(t, HashRefreshToken(token), hash)
+	if hash == token {
+		t.Error("expected the hash to differ from the token")
+	}
+	if HashRefreshToken(other) == hash {
+		t.Error("expected different tokens to have different hashes")
+	}
+}
+
 func assertEqual[T comparable](t *testing.T, got, want T) {
 	t.Helper()
 	if got != want {
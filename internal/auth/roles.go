@@ -0,0 +1,58 @@
+package auth
+
+import "fmt"
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionViewMetrics   Permission = "metrics:view"
+	PermissionResetData     Permission = "data:reset"
+	PermissionManageWords   Permission = "moderation:words"
+	PermissionReviewReports Permission = "moderation:reports"
+	PermissionManageRoles   Permission = "users:roles"
+	PermissionRestrictUsers Permission = "users:restrict"
+	PermissionViewAudit     Permission = "audit:view"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleModerator: {
+		PermissionViewMetrics,
+		PermissionManageWords,
+		PermissionReviewReports,
+	},
+	RoleAdmin: {
+		PermissionViewMetrics,
+		PermissionResetData,
+		PermissionManageWords,
+		PermissionReviewReports,
+		PermissionManageRoles,
+		PermissionRestrictUsers,
+		PermissionViewAudit,
+	},
+}
+
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return role, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// Can reports whether users with role r are allowed to do p.
+func (r Role) Can(p Permission) bool {
+	for _, permission := range rolePermissions[r] {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
@@ -2,17 +2,48 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Mailers that can be chosen with MAILER. Without one, features that need to
+// send email, such as password reset, are disabled.
+const (
+	MailerSMTP   = "smtp"
+	MailerOutbox = "outbox"
+	MailerNone   = "none"
+)
+
 type Config struct {
 	DBUrl       string
 	Platform    string
 	Secret      string
 	PolkaAPIKey string
 	Port        string
+
+	// EncryptionKey protects secrets stored in the database, such as TOTP
+	// keys. It falls back to SECRET, but changing it makes existing
+	// secrets unreadable, so it is best set on its own.
+	EncryptionKey string
+
+	ChirpEditWindow time.Duration
+
+	ProfanityReplacement    string
+	ProfanityReloadInterval time.Duration
+
+	// PublicURL is where users reach the app, links in emails point there.
+	PublicURL    string
+	Mailer       string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
 }
 
 func Load() (*Config, error) {
@@ -25,7 +56,41 @@ func Load() (*Config, error) {
 		Secret:      os.Getenv("SECRET"),
 		PolkaAPIKey: os.Getenv("POLKA_KEY"),
 		Port:        getEnvDefault("PORT", "8080"),
+
+		EncryptionKey: getEnvDefault("ENCRYPTION_KEY", os.Getenv("SECRET")),
+
+		ProfanityReplacement: getEnvDefault("PROFANITY_REPLACEMENT", "****"),
+
+		Mailer:       os.Getenv("MAILER"),
+		MailFrom:     getEnvDefault("MAIL_FROM", "Chirpy <no-reply@chirpy.local>"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnvDefault("SMTP_PORT", "587"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		OutboxDir:    os.Getenv("MAIL_OUTBOX_DIR"),
+	}
+	if cfg.Mailer == "" {
+		cfg.Mailer = MailerNone
+		if cfg.Platform == "dev" {
+			cfg.Mailer = MailerOutbox
+		}
+	}
+	cfg.PublicURL = strings.TrimSuffix(getEnvDefault("PUBLIC_URL", "http://localhost:"+cfg.Port), "/")
+
+	chirpEditWindow, err := time.ParseDuration(getEnvDefault("CHIRP_EDIT_WINDOW", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("CHIRP_EDIT_WINDOW must be a valid duration: %w", err)
 	}
+	cfg.ChirpEditWindow = chirpEditWindow
+
+	profanityReloadInterval, err := time.ParseDuration(getEnvDefault("PROFANITY_RELOAD_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("PROFANITY_RELOAD_INTERVAL must be a positive duration: %w", err)
+	}
+	if profanityReloadInterval <= 0 {
+		return nil, errors.New("PROFANITY_RELOAD_INTERVAL must be a positive duration")
+	}
+	cfg.ProfanityReloadInterval = profanityReloadInterval
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
@@ -47,6 +112,21 @@ func (c *Config) validate() error {
 	if c.PolkaAPIKey == "" {
 		return errors.New("POLKA_KEY must be set")
 	}
+	switch c.Mailer {
+	case MailerNone:
+	case MailerOutbox:
+		// The outbox logs messages, and with them the reset and verification
+		// links they carry, so it must never run in production.
+		if c.Platform != "dev" {
+			return errors.New("MAILER must be smtp unless PLATFORM is dev")
+		}
+	case MailerSMTP:
+		if c.SMTPHost == "" {
+			return errors.New("SMTP_HOST must be set when MAILER is smtp")
+		}
+	default:
+		return errors.New("MAILER must be smtp, outbox or none")
+	}
 	return nil
 }
 
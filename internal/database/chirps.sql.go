@@ -7,29 +7,33 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body      string
+	UserID    uuid.UUID
+	InReplyTo uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID, arg.InReplyTo)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,10 +41,68 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
 	)
 	return i, err
 }
 
+const createChirpReference = `-- name: CreateChirpReference :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, referenced_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id
+`
+
+type CreateChirpReferenceParams struct {
+	Body              string
+	UserID            uuid.UUID
+	Kind              string
+	ReferencedChirpID uuid.NullUUID
+}
+
+func (q *Queries) CreateChirpReference(ctx context.Context, arg CreateChirpReferenceParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createChirpReference, arg.Body, arg.UserID, arg.Kind, arg.ReferencedChirpID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
+	)
+	return i, err
+}
+
+const decrementChirpLikeCount = `-- name: DecrementChirpLikeCount :one
+UPDATE chirps
+SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1
+RETURNING like_count
+`
+
+func (q *Queries) DecrementChirpLikeCount(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, decrementChirpLikeCount, id)
+	var like_count int32
+	err := row.Scan(&like_count)
+	return like_count, err
+}
+
 const deleteChirp = `-- name: DeleteChirp :exec
 DELETE FROM chirps WHERE id = $1
 `
@@ -50,8 +112,18 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteRechirpsOf = `-- name: DeleteRechirpsOf :exec
+DELETE FROM chirps
+WHERE referenced_chirp_id = $1 AND kind = 'rechirp'
+`
+
+func (q *Queries) DeleteRechirpsOf(ctx context.Context, referencedChirpID uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, deleteRechirpsOf, referencedChirpID)
+	return err
+}
+
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
 WHERE id = $1
 `
 
@@ -64,17 +136,280 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
+	)
+	return i, err
+}
+
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to, 1 AS depth
+    FROM chirps child
+    INNER JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = $1::uuid
+    UNION ALL
+    SELECT chirps.id, chirps.in_reply_to, ancestors.depth + 1
+    FROM chirps
+    INNER JOIN ancestors ON chirps.id = ancestors.in_reply_to
+    WHERE ancestors.depth < $2::int
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.like_count, chirps.kind, chirps.referenced_chirp_id, ancestors.depth
+FROM ancestors
+INNER JOIN chirps ON chirps.id = ancestors.id
+WHERE NOT is_hidden_from($3::uuid, chirps.user_id)
+ORDER BY ancestors.depth DESC
+`
+
+type GetChirpAncestorsParams struct {
+	ChirpID  uuid.UUID
+	MaxDepth int32
+	ViewerID uuid.NullUUID
+}
+
+type GetChirpAncestorsRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpAncestors(ctx context.Context, arg GetChirpAncestorsParams) ([]GetChirpAncestorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, arg.ChirpID, arg.MaxDepth, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpAncestorsRow
+	for rows.Next() {
+		var i GetChirpAncestorsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.ReferencedChirpID,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpDescendants = `-- name: GetChirpDescendants :many
+WITH RECURSIVE replies AS (
+    SELECT chirps.id, 1 AS depth
+    FROM chirps
+    WHERE chirps.in_reply_to = $1::uuid
+    UNION ALL
+    SELECT chirps.id, replies.depth + 1
+    FROM chirps
+    INNER JOIN replies ON chirps.in_reply_to = replies.id
+    WHERE replies.depth < $2::int
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.like_count, chirps.kind, chirps.referenced_chirp_id, replies.depth
+FROM replies
+INNER JOIN chirps ON chirps.id = replies.id
+WHERE NOT is_hidden_from($3::uuid, chirps.user_id)
+ORDER BY replies.depth, chirps.created_at, chirps.id
+LIMIT $4
+`
+
+type GetChirpDescendantsParams struct {
+	ChirpID    uuid.UUID
+	MaxDepth   int32
+	ViewerID   uuid.NullUUID
+	MaxReplies int32
+}
+
+type GetChirpDescendantsRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpDescendants(ctx context.Context, arg GetChirpDescendantsParams) ([]GetChirpDescendantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpDescendants, arg.ChirpID, arg.MaxDepth, arg.ViewerID, arg.MaxReplies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpDescendantsRow
+	for rows.Next() {
+		var i GetChirpDescendantsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.ReferencedChirpID,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
+	)
+	return i, err
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE id = ANY($1::uuid[])
+AND NOT is_blocked_between($2::uuid, user_id)
+AND NOT (is_restricted(user_id, 'shadow_ban') AND user_id IS DISTINCT FROM $2::uuid)
+`
+
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.LikeCount,
+			&i.Kind,
+			&i.ReferencedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE user_id = $1 AND referenced_chirp_id = $2 AND kind = 'rechirp'
+`
+
+type GetRechirpParams struct {
+	UserID            uuid.UUID
+	ReferencedChirpID uuid.NullUUID
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.ReferencedChirpID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at
+const incrementChirpLikeCount = `-- name: IncrementChirpLikeCount :one
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id = $1
+RETURNING like_count
+`
+
+func (q *Queries) IncrementChirpLikeCount(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, incrementChirpLikeCount, id)
+	var like_count int32
+	err := row.Scan(&like_count)
+	return like_count, err
+}
+
+const listChirpsAsc = `-- name: ListChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) > ($2::timestamp, $3::uuid)
+)
+AND NOT is_hidden_from($4::uuid, user_id)
+ORDER BY created_at ASC, id ASC
+LIMIT $5
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type ListChirpsAscParams struct {
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsAsc, arg.AuthorID, arg.CursorCreatedAt, arg.CursorID, arg.ViewerID, arg.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +423,11 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.LikeCount,
+			&i.Kind,
+			&i.ReferencedChirpID,
 		); err != nil {
 			return nil, err
 		}
@@ -102,14 +442,28 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirpsByUserID = `-- name: GetChirpsByUserID :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+AND NOT is_hidden_from($4::uuid, user_id)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
 `
 
-func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUserID, userID)
+type ListChirpsDescParams struct {
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc, arg.AuthorID, arg.CursorCreatedAt, arg.CursorID, arg.ViewerID, arg.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +477,11 @@ func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.LikeCount,
+			&i.Kind,
+			&i.ReferencedChirpID,
 		); err != nil {
 			return nil, err
 		}
@@ -136,3 +495,147 @@ func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const listTimeline = `-- name: ListTimeline :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id FROM chirps
+WHERE (
+    user_id = $1
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+AND NOT is_hidden_from($1, user_id)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListTimelineParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListTimeline(ctx context.Context, arg ListTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTimeline, arg.UserID, arg.CursorCreatedAt, arg.CursorID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.LikeCount,
+			&i.Kind,
+			&i.ReferencedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.like_count, chirps.kind, chirps.referenced_chirp_id, ts_rank(chirps.search_vector, to_tsquery('english', $1)) AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', $1)
+AND ($2::uuid IS NULL OR chirps.user_id = $2::uuid)
+AND NOT is_hidden_from($3::uuid, chirps.user_id)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $4 OFFSET $5
+`
+
+type SearchChirpsParams struct {
+	Query      string
+	AuthorID   uuid.NullUUID
+	ViewerID   uuid.NullUUID
+	PageSize   int32
+	PageOffset int32
+}
+
+type SearchChirpsRow struct {
+	Chirp Chirp
+	Rank  float32
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps, arg.Query, arg.AuthorID, arg.ViewerID, arg.PageSize, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.ReferencedChirpID,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to, like_count, kind, referenced_chirp_id
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.LikeCount,
+		&i.Kind,
+		&i.ReferencedChirpID,
+	)
+	return i, err
+}
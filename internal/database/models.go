@@ -6,33 +6,225 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AuditEvent struct {
+	ID        uuid.UUID
+	Action    string
+	ActorID   uuid.NullUUID
+	TargetID  uuid.NullUUID
+	Ip        string
+	UserAgent string
+	RequestID string
+	Details   json.RawMessage
+	CreatedAt time.Time
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Body              string
+	UserID            uuid.UUID
+	SearchVector      interface{}
+	InReplyTo         uuid.NullUUID
+	LikeCount         int32
+	Kind              string
+	ReferencedChirpID uuid.NullUUID
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
 	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+type Conversation struct {
+	ID        uuid.UUID
+	DirectKey sql.NullString
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
+}
+
+type ConversationMember struct {
+	ConversationID uuid.UUID
+	UserID         uuid.UUID
+	JoinedAt       time.Time
+	LastReadAt     sql.NullTime
+}
+
+type EmailVerificationToken struct {
+	TokenHash string
 	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+	CreatedAt      time.Time
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type MutedWord struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Phrase    string
+	WholeWord bool
+	ExpiresAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ActorID   uuid.NullUUID
+	Kind      string
+	ChirpID   uuid.NullUUID
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type ProfaneWord struct {
+	ID        uuid.UUID
+	Word      string
+	WholeWord bool
+	CreatedAt time.Time
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
-	Token     string
+	TokenHash string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	ExpiresAt time.Time
 	RevokedAt sql.NullTime
+	FamilyID  uuid.UUID
+	RotatedAt sql.NullTime
 }
 
-type User struct {
+type Report struct {
 	ID             uuid.UUID
+	ChirpID        uuid.NullUUID
+	ChirpAuthorID  uuid.UUID
+	ChirpBody      string
+	ReporterID     uuid.UUID
+	Reason         string
+	Details        string
+	Status         string
+	ResolvedBy     uuid.NullUUID
+	ResolutionNote string
+	ResolvedAt     sql.NullTime
 	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+}
+
+type Session struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Ip         string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+type User struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  string
+	IsChirpyRed     bool
+	Role            string
+	EmailVerifiedAt sql.NullTime
+}
+
+type UserRestriction struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Kind       string
+	Reason     string
+	CreatedBy  uuid.NullUUID
+	ExpiresAt  sql.NullTime
+	LiftedAt   sql.NullTime
+	LiftedBy   uuid.NullUUID
+	LiftReason string
+	CreatedAt  time.Time
+}
+
+type UserTotp struct {
+	UserID           uuid.UUID
+	SecretCiphertext string
+	ConfirmedAt      sql.NullTime
+	LastUsedStep     int64
+	FailedAttempts   int32
+	LastFailedAt     sql.NullTime
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
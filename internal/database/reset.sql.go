@@ -7,13 +7,24 @@ package database
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
-const deleteUsers = `-- name: DeleteUsers :exec
-DELETE FROM users
+const deleteOtherUsers = `-- name: DeleteOtherUsers :exec
+DELETE FROM users WHERE id <> $1
+`
+
+func (q *Queries) DeleteOtherUsers(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherUsers, id)
+	return err
+}
+
+const deleteUserChirps = `-- name: DeleteUserChirps :exec
+DELETE FROM chirps WHERE user_id = $1
 `
 
-func (q *Queries) DeleteUsers(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteUsers)
+func (q *Queries) DeleteUserChirps(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserChirps, userID)
 	return err
 }
@@ -0,0 +1,65 @@
+// Package encryption protects small secrets stored in the database, such as
+// TOTP keys, with AES-256-GCM.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+var ErrDecrypt = errors.New("encryption: couldn't decrypt value")
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New derives a key for purpose from secret with HKDF-SHA256, so one
+// configured secret can protect several kinds of data with separate keys.
+func New(secret, purpose string) (*Box, error) {
+	if secret == "" {
+		return nil, errors.New("encryption: secret must not be empty")
+	}
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, purpose, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext and binds it to additionalData, which must be
+// passed to Open again. Using the owning row's ID stops a ciphertext from
+// being copied to another row.
+func (b *Box) Seal(plaintext, additionalData []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, plaintext, additionalData)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *Box) Open(ciphertext string, additionalData []byte) ([]byte, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
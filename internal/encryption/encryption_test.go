@@ -0,0 +1,74 @@
+package encryption
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	box, err := New("config secret", "totp")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sealed, err := box.Seal([]byte("JBSWY3DPEHPK3PXP"), []byte("user-1"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	otherKey, _ := New("other secret", "totp")
+	otherPurpose, _ := New("config secret", "backups")
+	tampered := []byte(sealed)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name       string
+		box        *Box
+		ciphertext string
+		ad         string
+		wantErr    bool
+	}{
+		{name: "round trip", box: box, ciphertext: sealed, ad: "user-1"},
+		{name: "other row", box: box, ciphertext: sealed, ad: "user-2", wantErr: true},
+		{name: "other secret", box: otherKey, ciphertext: sealed, ad: "user-1", wantErr: true},
+		{name: "other purpose", box: otherPurpose, ciphertext: sealed, ad: "user-1", wantErr: true},
+		{name: "tampered", box: box, ciphertext: string(tampered), ad: "user-1", wantErr: true},
+		{name: "not base64", box: box, ciphertext: "!!!", ad: "user-1", wantErr: true},
+		{name: "too short", box: box, ciphertext: "AAAA", ad: "user-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.box.Open(tt.ciphertext, []byte(tt.ad))
+			if tt.wantErr {
+				if !errors.Is(err, ErrDecrypt) {
+					t.Errorf("Open() error = %v, want ErrDecrypt", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if string(got) != "JBSWY3DPEHPK3PXP" {
+				t.Errorf("Open() = %q, want the sealed secret", got)
+			}
+		})
+	}
+}
+
+func TestSealUsesFreshNonce(t *testing.T) {
+	box, err := New("config secret", "totp")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a, _ := box.Seal([]byte("same"), nil)
+	b, _ := box.Seal([]byte("same"), nil)
+	if a == b {
+		t.Errorf("Seal() returned the same ciphertext twice")
+	}
+}
+
+func TestNewRejectsEmptySecret(t *testing.T) {
+	if _, err := New("", "totp"); err == nil {
+		t.Errorf("New() with empty secret succeeded")
+	}
+}
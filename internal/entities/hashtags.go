@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxHashtagLength = 64
+
+// Hashtags returns the normalized hashtags in body in order of first
+// appearance. A hashtag starts with # at the beginning of a word and runs
+// until the first character that isn't a letter, digit or underscore.
+func Hashtags(body string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	prev := ' '
+	for i, r := range body {
+		if r != '#' || isTagRune(prev) || prev == '#' {
+			prev = r
+			continue
+		}
+		prev = r
+		end := i + 1
+		for end < len(body) {
+			next, size := utf8.DecodeRuneInString(body[end:])
+			if !isTagRune(next) {
+				break
+			}
+			end += size
+		}
+		tag, ok := NormalizeHashtag(body[i+1 : end])
+		if ok && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// NormalizeHashtag lowercases tag and strips a leading #. Tags must contain at
+// least one letter so that "#1" or "#2024" aren't treated as hashtags.
+func NormalizeHashtag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxHashtagLength {
+		return "", false
+	}
+	hasLetter := false
+	for _, r := range tag {
+		if !isTagRune(r) {
+			return "", false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	if !hasLetter {
+		return "", false
+	}
+	return tag, true
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
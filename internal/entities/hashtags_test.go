@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "none", body: "just a chirp", want: nil},
+		{name: "single", body: "learning #Go today", want: []string{"go"}},
+		{name: "punctuation ends tag", body: "#golang, #rust!", want: []string{"golang", "rust"}},
+		{name: "duplicates", body: "#Go #go #GO", want: []string{"go"}},
+		{name: "adjacent tags", body: "#one#two", want: []string{"one"}},
+		{name: "inside word", body: "issue#42 and c#", want: nil},
+		{name: "numbers only", body: "#2024 #1", want: nil},
+		{name: "underscore and digits", body: "#go_1_24", want: []string{"go_1_24"}},
+		{name: "unicode", body: "#Über #café", want: []string{"über", "café"}},
+		{name: "double hash", body: "##go", want: nil},
+		{name: "too long", body: "#" + strings.Repeat("a", MaxHashtagLength+1), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hashtags(tt.body)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{tag: "#GoLang", want: "golang", wantOK: true},
+		{tag: "golang", want: "golang", wantOK: true},
+		{tag: "#", wantOK: false},
+		{tag: "go lang", wantOK: false},
+		{tag: "123", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, ok := NormalizeHashtag(tt.tag)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("NormalizeHashtag(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
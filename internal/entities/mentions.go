@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mentions returns the lowercased email addresses mentioned in body as
+// @address, in order of first appearance. Users don't have handles, so an
+// email address is the only way to refer to someone.
+func Mentions(body string) []string {
+	var mentions []string
+	seen := map[string]bool{}
+	prev := ' '
+	for i, r := range body {
+		if r != '@' || isAddressRune(prev) {
+			prev = r
+			continue
+		}
+		prev = r
+		end := i + 1
+		for end < len(body) {
+			next, size := utf8.DecodeRuneInString(body[end:])
+			if !isAddressRune(next) {
+				break
+			}
+			end += size
+		}
+		address := strings.ToLower(strings.TrimRight(body[i+1:end], ".-"))
+		if isAddress(address) && !seen[address] {
+			seen[address] = true
+			mentions = append(mentions, address)
+		}
+	}
+	return mentions
+}
+
+func isAddress(address string) bool {
+	local, domain, found := strings.Cut(address, "@")
+	if !found || local == "" || strings.Contains(domain, "@") {
+		return false
+	}
+	if strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") || strings.Contains(domain, "..") {
+		return false
+	}
+	return strings.Contains(domain, ".")
+}
+
+func isAddressRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._%+-@", r)
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "none", body: "no mentions here", want: nil},
+		{name: "single", body: "hi @Alice@Example.com!", want: []string{"alice@example.com"}},
+		{name: "trailing period", body: "thanks @bob@example.com.", want: []string{"bob@example.com"}},
+		{name: "duplicates", body: "@a@b.io @A@B.io", want: []string{"a@b.io"}},
+		{name: "several", body: "@a@b.io, @c+chirpy@d.co", want: []string{"a@b.io", "c+chirpy@d.co"}},
+		{name: "plain email", body: "mail me at alice@example.com", want: nil},
+		{name: "no domain dot", body: "@alice@localhost", want: nil},
+		{name: "handle only", body: "@alice", want: nil},
+		{name: "double at", body: "@@alice@example.com", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mentions(tt.body)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
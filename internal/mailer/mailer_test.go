@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "plain message",
+			msg:  Message{To: "user@example.com", Subject: "Hello", Body: "line one\nline two"},
+			want: []string{
+				"From: Chirpy <no-reply@example.com>\r\n",
+				"To: user@example.com\r\n",
+				"Subject: Hello\r\n",
+				"\r\n\r\nline one\r\nline two",
+			},
+		},
+		{
+			name: "non-ascii subject",
+			msg:  Message{To: "user@example.com", Subject: "Grüße", Body: "hi"},
+			want: []string{"Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n"},
+		},
+		{
+			name:    "header injection in recipient",
+			msg:     Message{To: "user@example.com\r\nBcc: other@example.com", Subject: "Hello"},
+			wantErr: true,
+		},
+		{
+			name:    "header injection in subject",
+			msg:     Message{To: "user@example.com", Subject: "Hello\nBcc: other@example.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := format("Chirpy <no-reply@example.com>", tt.msg, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("format() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	outbox := NewOutbox(t.TempDir(), "no-reply@example.com")
+	for _, subject := range []string{"first", "second"} {
+		err := outbox.Send(context.Background(), Message{To: "user@example.com", Subject: subject, Body: "hi"})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	messages, err := outbox.Messages()
+	if err != nil {
+		t.Fatalf("Messages() error = %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	if !strings.Contains(string(messages[0]), "Subject: first") || !strings.Contains(string(messages[1]), "Subject: second") {
+		t.Errorf("messages out of order: %q", messages)
+	}
+}
+
+func TestSMTPSendHonoursContext(t *testing.T) {
+	// A server that accepts connections but never sends its greeting.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// Hold each connection open until the client gives up.
+			go func(conn net.Conn) {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	m := NewSMTP(host, port, "", "", "no-reply@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = m.Send(ctx, Message{To: "user@example.com", Subject: "hi", Body: "hi"})
+	if err == nil {
+		t.Fatal("Send() succeeded against a server that never answered")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() took %s, want it to give up with the context", elapsed)
+	}
+}
@@ -1,8 +1,15 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+	"github.com/markoc1120/go_server/internal/response"
 )
 
 func MetricsInc(counter *atomic.Int32) func(http.Handler) http.Handler {
@@ -13,3 +20,124 @@ func MetricsInc(counter *atomic.Int32) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+type contextKey int
+
+const (
+	claimsKey contextKey = iota
+	requestIDKey
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 64
+)
+
+// RequirePermission only lets requests through whose caller currently has a
+// role with permission. The role is read with currentRole rather than taken
+// from the access token, so a demotion applies before the token expires.
+// The token's claims, carrying the current role, are available to the next
+// handler through Claims.
+func RequirePermission(tokenSecret string, currentRole func(ctx context.Context, userID uuid.UUID) (auth.Role, error), permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := auth.GetBearerToken(r.Header)
+			if err != nil {
+				response.WithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+				return
+			}
+			claims, err := auth.ValidateAccessToken(token, tokenSecret)
+			if err != nil {
+				response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+				return
+			}
+			claims.Role, err = currentRole(r.Context(), claims.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				response.WithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+				return
+			}
+			if err != nil {
+				response.WithError(w, http.StatusInternalServerError, "Couldn't check permissions", err)
+				return
+			}
+			if !claims.Role.Can(permission) {
+				response.WithError(w, http.StatusForbidden, "You don't have permission to do this", nil)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+		})
+	}
+}
+
+// Claims returns the access token claims stored by RequirePermission.
+func Claims(ctx context.Context) (auth.AccessClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(auth.AccessClaims)
+	return claims, ok
+}
+
+// RejectSuspendedWrites refuses requests that change data when they carry an
+// access token of a suspended user. suspension returns the message to refuse
+// them with, or an empty string when the user isn't suspended. Reads and
+// requests without an access token go through.
+func RejectSuspendedWrites(tokenSecret string, suspension func(ctx context.Context, userID uuid.UUID) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			token, err := auth.GetBearerToken(r.Header)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, err := auth.ValidateAccessToken(token, tokenSecret)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			msg, err := suspension(r.Context(), claims.UserID)
+			if err != nil {
+				response.WithError(w, http.StatusInternalServerError, "Couldn't check account status", err)
+				return
+			}
+			if msg != "" {
+				response.WithError(w, http.StatusForbidden, msg, nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestID tags every request with an ID, reusing the X-Request-ID header
+// when the client sent a usable one. The ID is echoed back in the response and
+// available to handlers through RequestIDFrom.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// RequestIDFrom returns the ID stored by RequestID.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
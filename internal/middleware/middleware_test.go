@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/auth"
+)
+
+func TestRequirePermission(t *testing.T) {
+	const secret = "secret"
+	userID := uuid.New()
+	makeToken := func(role auth.Role, secret string) string {
+		token, err := auth.MakeJWT(userID, role, secret, time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT() error = %v", err)
+		}
+		return token
+	}
+	roleIs := func(role auth.Role, err error) func(context.Context, uuid.UUID) (auth.Role, error) {
+		return func(ctx context.Context, id uuid.UUID) (auth.Role, error) {
+			if id != userID {
+				t.Errorf("currentRole() called for %s, want %s", id, userID)
+			}
+			return role, err
+		}
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		currentRole   func(context.Context, uuid.UUID) (auth.Role, error)
+		wantStatus    int
+		wantRole      auth.Role
+	}{
+		{name: "no token", authorization: "", currentRole: roleIs(auth.RoleAdmin, nil), wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", authorization: "Bearer " + makeToken(auth.RoleAdmin, "other"), currentRole: roleIs(auth.RoleAdmin, nil), wantStatus: http.StatusUnauthorized},
+		{name: "user", authorization: "Bearer " + makeToken(auth.RoleUser, secret), currentRole: roleIs(auth.RoleUser, nil), wantStatus: http.StatusForbidden},
+		{name: "moderator", authorization: "Bearer " + makeToken(auth.RoleModerator, secret), currentRole: roleIs(auth.RoleModerator, nil), wantStatus: http.StatusOK, wantRole: auth.RoleModerator},
+		{name: "admin", authorization: "Bearer " + makeToken(auth.RoleAdmin, secret), currentRole: roleIs(auth.RoleAdmin, nil), wantStatus: http.StatusOK, wantRole: auth.RoleAdmin},
+		{name: "demoted since token was issued", authorization: "Bearer " + makeToken(auth.RoleAdmin, secret), currentRole: roleIs(auth.RoleUser, nil), wantStatus: http.StatusForbidden},
+		{name: "promoted since token was issued", authorization: "Bearer " + makeToken(auth.RoleUser, secret), currentRole: roleIs(auth.RoleModerator, nil), wantStatus: http.StatusOK, wantRole: auth.RoleModerator},
+		{name: "deleted user", authorization: "Bearer " + makeToken(auth.RoleAdmin, secret), currentRole: roleIs("", sql.ErrNoRows), wantStatus: http.StatusUnauthorized},
+		{name: "lookup fails", authorization: "Bearer " + makeToken(auth.RoleAdmin, secret), currentRole: roleIs("", errors.New("db down")), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := Claims(r.Context())
+				if !ok || claims.UserID != userID || claims.Role != tt.wantRole {
+					t.Errorf("Claims() = %+v, %v", claims, ok)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RequirePermission(secret, tt.currentRole, auth.PermissionReviewReports)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/reports", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRejectSuspendedWrites(t *testing.T) {
+	const secret = "secret"
+	suspendedID := uuid.New()
+	activeID := uuid.New()
+	makeToken := func(userID uuid.UUID) string {
+		token, err := auth.MakeJWT(userID, auth.RoleUser, secret, time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT() error = %v", err)
+		}
+		return "Bearer " + token
+	}
+	suspension := func(ctx context.Context, userID uuid.UUID) (string, error) {
+		if userID == suspendedID {
+			return "Your account is suspended: spam", nil
+		}
+		return "", nil
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "suspended write", method: http.MethodPost, authorization: makeToken(suspendedID), wantStatus: http.StatusForbidden},
+		{name: "suspended delete", method: http.MethodDelete, authorization: makeToken(suspendedID), wantStatus: http.StatusForbidden},
+		{name: "suspended read", method: http.MethodGet, authorization: makeToken(suspendedID), wantStatus: http.StatusOK},
+		{name: "active write", method: http.MethodPost, authorization: makeToken(activeID), wantStatus: http.StatusOK},
+		{name: "no token", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "refresh token", method: http.MethodPost, authorization: "Bearer 0123abcd", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RejectSuspendedWrites(secret, suspension)(next)
+
+			req := httptest.NewRequest(tt.method, "/api/chirps", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "none", incoming: "", wantSame: false},
+		{name: "client id", incoming: "abc-123_x.y", wantSame: true},
+		{name: "unsafe characters", incoming: "abc\r\nSet-Cookie: x", wantSame: false},
+		{name: "too long", incoming: strings.Repeat("a", maxRequestIDLength+1), wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RequestIDFrom(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/healthz", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got == "" {
+				t.Fatal("expected a request ID")
+			}
+			if (got == tt.incoming) != tt.wantSame {
+				t.Errorf("RequestIDFrom() = %q, incoming %q", got, tt.incoming)
+			}
+			if header := rec.Header().Get(RequestIDHeader); header != got {
+				t.Errorf("response header = %q, want %q", header, got)
+			}
+		})
+	}
+}
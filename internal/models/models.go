@@ -1,25 +1,81 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Email       string    `json:"email"`
-	IsChirpyRed bool      `json:"is_chirpy_red"`
+	ID            uuid.UUID `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	IsChirpyRed   bool      `json:"is_chirpy_red"`
+	Role          string    `json:"role"`
+}
+
+type UpdatedUser struct {
+	User
+	PendingEmail string `json:"pending_email,omitempty"`
 }
 
 type Chirp struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	UserID    uuid.UUID  `json:"user_id"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+	Edited    bool       `json:"edited"`
+	LikeCount int32      `json:"like_count"`
+	LikedByMe bool       `json:"liked_by_me"`
+
+	Kind                   string     `json:"kind"`
+	ReferencedChirpID      *uuid.UUID `json:"referenced_chirp_id"`
+	ReferencedChirp        *Chirp     `json:"referenced_chirp,omitempty"`
+	ReferencedChirpDeleted bool       `json:"referenced_chirp_deleted,omitempty"`
+}
+
+type ChirpLikes struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	LikeCount int32     `json:"like_count"`
+	LikedByMe bool      `json:"liked_by_me"`
+}
+
+type ChirpReply struct {
+	Chirp
+	Replies []ChirpReply `json:"replies"`
+}
+
+type ChirpThread struct {
+	Ancestors []Chirp      `json:"ancestors"`
+	Chirp     Chirp        `json:"chirp"`
+	Replies   []ChirpReply `json:"replies"`
+}
+
+type ChirpRevision struct {
 	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
 	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChirpPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type Follow struct {
 	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FollowPage struct {
+	Users      []Follow `json:"users"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
 
 type LoggedInUser struct {
@@ -44,12 +100,60 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type TwoFactorChallenge struct {
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code, or a recovery code where the
+// endpoint accepts one.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 type CreateChirpRequest struct {
+	Body      string     `json:"body"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+}
+
+type QuoteChirpRequest struct {
+	Body string `json:"body"`
+}
+
+type UpdateChirpRequest struct {
 	Body string `json:"body"`
 }
 
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type PolkaWebhookRequest struct {
@@ -58,3 +162,201 @@ type PolkaWebhookRequest struct {
 		UserID string `json:"user_id"`
 	} `json:"data"`
 }
+
+type WSClientMessage struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+type WSServerMessage struct {
+	Type    string          `json:"type"`
+	Channel string          `json:"channel,omitempty"`
+	ID      uint64          `json:"id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+type HashtagTrend struct {
+	Tag         string `json:"tag"`
+	ChirpCount  int64  `json:"chirp_count"`
+	AuthorCount int64  `json:"author_count"`
+}
+
+type TrendingHashtags struct {
+	Since    time.Time      `json:"since"`
+	Hashtags []HashtagTrend `json:"hashtags"`
+}
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	Kind      string     `json:"kind"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	ChirpID   *uuid.UUID `json:"chirp_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+type UnreadNotifications struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+	All bool        `json:"all"`
+}
+
+type MarkNotificationsReadResponse struct {
+	MarkedRead  int64 `json:"marked_read"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type Conversation struct {
+	ID          uuid.UUID   `json:"id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	MemberIDs   []uuid.UUID `json:"member_ids"`
+	UnreadCount int64       `json:"unread_count"`
+}
+
+type ConversationPage struct {
+	Conversations []Conversation `json:"conversations"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+type Message struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+type CreateConversationRequest struct {
+	MemberIDs []uuid.UUID `json:"member_ids"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
+
+type MutedWord struct {
+	ID        uuid.UUID  `json:"id"`
+	Phrase    string     `json:"phrase"`
+	WholeWord bool       `json:"whole_word"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type MuteWordRequest struct {
+	Phrase    string     `json:"phrase"`
+	WholeWord *bool      `json:"whole_word"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ProfaneWord struct {
+	ID        uuid.UUID `json:"id"`
+	Word      string    `json:"word"`
+	WholeWord bool      `json:"whole_word"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateProfaneWordRequest struct {
+	Word      string `json:"word"`
+	WholeWord *bool  `json:"whole_word"`
+}
+
+type Report struct {
+	ID             uuid.UUID  `json:"id"`
+	ChirpID        *uuid.UUID `json:"chirp_id,omitempty"`
+	ChirpAuthorID  uuid.UUID  `json:"chirp_author_id"`
+	ChirpBody      string     `json:"chirp_body"`
+	ReporterID     uuid.UUID  `json:"reporter_id"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details,omitempty"`
+	Status         string     `json:"status"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ReportPage struct {
+	Reports    []Report `json:"reports"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+type CreateReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type ReportActionRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type UserRestriction struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Kind       string     `json:"kind"`
+	Reason     string     `json:"reason"`
+	Active     bool       `json:"active"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LiftedAt   *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy   *uuid.UUID `json:"lifted_by,omitempty"`
+	LiftReason string     `json:"lift_reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateRestrictionRequest struct {
+	Kind      string     `json:"kind"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type LiftRestrictionRequest struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+type AuditEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	Action    string          `json:"action"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty"`
+	TargetID  *uuid.UUID      `json:"target_id,omitempty"`
+	IP        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+	RequestID string          `json:"request_id"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditEventPage struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,91 @@
+// Package moderation masks profane words in chirps. The word list lives in the
+// database so admins can change it while the server is running.
+package moderation
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/textmatch"
+)
+
+type Filter struct {
+	db          *database.Queries
+	replacement string
+
+	mu      sync.RWMutex
+	matcher *textmatch.Matcher
+}
+
+// NewFilter returns a Filter that replaces profane words with replacement. It
+// matches nothing until the word list is loaded with Reload or Set.
+func NewFilter(db *database.Queries, replacement string) *Filter {
+	return &Filter{
+		db:          db,
+		replacement: replacement,
+		matcher:     textmatch.New(nil),
+	}
+}
+
+// Reload replaces the active word list with the one stored in the database.
+func (f *Filter) Reload(ctx context.Context) error {
+	words, err := f.db.ListProfaneWords(ctx)
+	if err != nil {
+		return err
+	}
+	rules := make([]textmatch.Rule, 0, len(words))
+	for _, word := range words {
+		rules = append(rules, textmatch.Rule{Phrase: word.Word, WholeWord: word.WholeWord})
+	}
+	f.Set(rules)
+	return nil
+}
+
+// Watch reloads the word list every interval until ctx is done, so changes
+// made through other server instances are picked up.
+func (f *Filter) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Reload(ctx); err != nil {
+				log.Printf("Couldn't reload profane words: %s", err)
+			}
+		}
+	}
+}
+
+func (f *Filter) Set(rules []textmatch.Rule) {
+	matcher := textmatch.New(rules)
+	f.mu.Lock()
+	f.matcher = matcher
+	f.mu.Unlock()
+}
+
+// Clean replaces every profane word in body with the replacement.
+func (f *Filter) Clean(body string) string {
+	f.mu.RLock()
+	matcher := f.matcher
+	f.mu.RUnlock()
+
+	ranges := matcher.FindAll(body)
+	if len(ranges) == 0 {
+		return body
+	}
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		b.WriteString(body[last:r.Start])
+		b.WriteString(f.replacement)
+		last = r.End
+	}
+	b.WriteString(body[last:])
+	return b.String()
+}
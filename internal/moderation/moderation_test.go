@@ -0,0 +1,74 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/markoc1120/go_server/internal/textmatch"
+)
+
+func TestClean(t *testing.T) {
+	rules := []textmatch.Rule{
+		{Phrase: "kerfuffle", WholeWord: true},
+		{Phrase: "sharbert", WholeWord: true},
+		{Phrase: "fornax", WholeWord: true},
+		{Phrase: "heck", WholeWord: false},
+	}
+	tests := []struct {
+		body string
+		want string
+	}{
+		{
+			body: "I had something interesting for breakfast",
+			want: "I had something interesting for breakfast",
+		},
+		{
+			body: "I hear Mastodon is better than Chirpy. sharbert I need to migrate",
+			want: "I hear Mastodon is better than Chirpy. **** I need to migrate",
+		},
+		{
+			body: "I really need a kerfuffle to go to bed sooner, Fornax !",
+			want: "I really need a **** to go to bed sooner, **** !",
+		},
+		{
+			body: "What a Kerfuffle! Pass the sharbert, please",
+			want: "What a ****! Pass the ****, please",
+		},
+		{
+			body: "Kérfuffle and ｆｏｒｎａｘ",
+			want: "**** and ****",
+		},
+		{
+			body: "kerfuffles are fine",
+			want: "kerfuffles are fine",
+		},
+		{
+			body: "what the heckin heck",
+			want: "what the ****in ****",
+		},
+	}
+
+	f := NewFilter(nil, "****")
+	f.Set(rules)
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			if got := f.Clean(test.body); got != test.want {
+				t.Errorf("Clean() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCleanReplacement(t *testing.T) {
+	f := NewFilter(nil, "[removed]")
+	f.Set([]textmatch.Rule{{Phrase: "fornax", WholeWord: true}})
+	if got, want := f.Clean("oh fornax."), "oh [removed]."; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
+
+func TestCleanWithoutWords(t *testing.T) {
+	f := NewFilter(nil, "****")
+	if got, want := f.Clean("kerfuffle"), "kerfuffle"; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
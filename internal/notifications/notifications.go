@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/models"
+	"github.com/markoc1120/go_server/internal/pagination"
+	"github.com/markoc1120/go_server/internal/pubsub"
+)
+
+const (
+	KindFollow    = "follow"
+	KindLike      = "like"
+	KindReply     = "reply"
+	KindMention   = "mention"
+	KindChirpyRed = "chirpy_red"
+)
+
+// Notification describes something that happened to UserID. ActorID is the
+// user who caused it and is empty for system events such as upgrades.
+type Notification struct {
+	UserID  uuid.UUID
+	ActorID uuid.NullUUID
+	Kind    string
+	ChirpID uuid.NullUUID
+}
+
+type Service struct {
+	db     *database.Queries
+	events *pubsub.Hub
+}
+
+func NewService(db *database.Queries, events *pubsub.Hub) *Service {
+	return &Service{db: db, events: events}
+}
+
+// Topic is the pubsub topic live notifications for userID are published on.
+func Topic(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+// Record stores n using q so that it commits or rolls back together with the
+// caller's transaction. Users aren't notified about their own actions or about
+// actions of users they blocked, muted or were blocked by. In that case ok is
+// false and nothing is stored.
+func (s *Service) Record(ctx context.Context, q *database.Queries, n Notification) (notification database.Notification, ok bool, err error) {
+	if n.ActorID.Valid {
+		if n.ActorID.UUID == n.UserID {
+			return database.Notification{}, false, nil
+		}
+		hidden, err := q.IsHiddenFrom(ctx, database.IsHiddenFromParams{
+			ViewerID: n.UserID,
+			AuthorID: n.ActorID.UUID,
+		})
+		if err != nil || hidden {
+			return database.Notification{}, false, err
+		}
+	}
+	notification, err = q.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:  n.UserID,
+		ActorID: n.ActorID,
+		Kind:    n.Kind,
+		ChirpID: n.ChirpID,
+	})
+	if err != nil {
+		return database.Notification{}, false, err
+	}
+	return notification, true, nil
+}
+
+// Publish pushes recorded notifications to their recipients' live streams. It
+// should only be called once the notifications have been committed.
+func (s *Service) Publish(notifications ...database.Notification) {
+	for _, notification := range notifications {
+		data, err := json.Marshal(FromDB(notification))
+		if err != nil {
+			log.Printf("Error marshalling notification: %s", err)
+			continue
+		}
+		s.events.Publish(Topic(notification.UserID), data)
+	}
+}
+
+func (s *Service) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int32, cursor *pagination.Cursor) (models.NotificationPage, error) {
+	cursorCreatedAt, cursorID := cursor.QueryArgs()
+	notifications, err := s.db.ListNotifications(ctx, database.ListNotificationsParams{
+		UserID:          userID,
+		UnreadOnly:      unreadOnly,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        limit + 1,
+	})
+	if err != nil {
+		return models.NotificationPage{}, err
+	}
+
+	page := models.NotificationPage{Notifications: []models.Notification{}}
+	if len(notifications) > int(limit) {
+		notifications = notifications[:limit]
+		last := notifications[len(notifications)-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, notification := range notifications {
+		page.Notifications = append(page.Notifications, FromDB(notification))
+	}
+	return page, nil
+}
+
+func (s *Service) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.db.CountUnreadNotifications(ctx, userID)
+}
+
+// MarkRead marks the given notifications as read, or every unread
+// notification when ids is nil. Ids belonging to other users are ignored.
+func (s *Service) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	return s.db.MarkNotificationsRead(ctx, database.MarkNotificationsReadParams{
+		UserID: userID,
+		Ids:    ids,
+	})
+}
+
+func FromDB(n database.Notification) models.Notification {
+	notification := models.Notification{
+		ID:        n.ID,
+		Kind:      n.Kind,
+		CreatedAt: n.CreatedAt,
+		Read:      n.ReadAt.Valid,
+	}
+	if n.ActorID.Valid {
+		notification.ActorID = &n.ActorID.UUID
+	}
+	if n.ChirpID.Valid {
+		notification.ChirpID = &n.ChirpID.UUID
+	}
+	if n.ReadAt.Valid {
+		notification.ReadAt = &n.ReadAt.Time
+	}
+	return notification
+}
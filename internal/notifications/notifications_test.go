@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/database"
+)
+
+func TestRecordSkipsOwnActions(t *testing.T) {
+	userID := uuid.New()
+	s := NewService(nil, nil)
+
+	// A nil Queries would panic if Record tried to store anything.
+	_, ok, err := s.Record(context.Background(), nil, Notification{
+		UserID:  userID,
+		ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+		Kind:    KindLike,
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if ok {
+		t.Error("expected notification about own action to be skipped")
+	}
+}
+
+func TestFromDB(t *testing.T) {
+	actorID := uuid.New()
+	readAt := time.Now()
+	tests := []struct {
+		name      string
+		in        database.Notification
+		wantActor bool
+		wantChirp bool
+		wantRead  bool
+	}{
+		{
+			name: "system notification",
+			in:   database.Notification{ID: uuid.New(), Kind: KindChirpyRed},
+		},
+		{
+			name: "read like",
+			in: database.Notification{
+				ID:      uuid.New(),
+				Kind:    KindLike,
+				ActorID: uuid.NullUUID{UUID: actorID, Valid: true},
+				ChirpID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+				ReadAt:  sql.NullTime{Time: readAt, Valid: true},
+			},
+			wantActor: true,
+			wantChirp: true,
+			wantRead:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromDB(tt.in)
+			if got.ID != tt.in.ID || got.Kind != tt.in.Kind {
+				t.Errorf("got %+v, want id %s kind %s", got, tt.in.ID, tt.in.Kind)
+			}
+			if (got.ActorID != nil) != tt.wantActor {
+				t.Errorf("ActorID = %v, want set %v", got.ActorID, tt.wantActor)
+			}
+			if (got.ChirpID != nil) != tt.wantChirp {
+				t.Errorf("ChirpID = %v, want set %v", got.ChirpID, tt.wantChirp)
+			}
+			if got.Read != tt.wantRead || (got.ReadAt != nil) != tt.wantRead {
+				t.Errorf("Read = %v, ReadAt = %v, want read %v", got.Read, got.ReadAt, tt.wantRead)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("limit must be between 1 and " + strconv.Itoa(MaxLimit))
+)
+
+// Cursor points at the last row of a page ordered by (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return Cursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{CreatedAt: t, ID: parsedID}, nil
+}
+
+// QueryArgs returns the cursor as nullable query arguments, a nil cursor
+// selects the first page.
+func (c *Cursor) QueryArgs() (sql.NullTime, uuid.NullUUID) {
+	if c == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, uuid.NullUUID{UUID: c.ID, Valid: true}
+}
+
+// Parse reads the limit and cursor query parameters.
+func Parse(query url.Values) (int32, *Cursor, error) {
+	limit, err := ParseLimit(query.Get("limit"))
+	if err != nil {
+		return 0, nil, err
+	}
+	encoded := query.Get("cursor")
+	if encoded == "" {
+		return limit, nil, nil
+	}
+	cursor, err := DecodeCursor(encoded)
+	if err != nil {
+		return 0, nil, err
+	}
+	return limit, &cursor, nil
+}
+
+func ParseLimit(limit string) (int32, error) {
+	if limit == "" {
+		return DefaultLimit, nil
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n < 1 || n > MaxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return int32(n), nil
+}
+
+// EncodeOffset returns an opaque cursor for listings that can't be keyset
+// paginated, such as search results ordered by rank.
+func EncodeOffset(offset int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset|" + strconv.Itoa(int(offset))))
+}
+
+func DecodeOffset(encoded string) (int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	n, found := strings.CutPrefix(string(raw), "offset|")
+	if !found {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.ParseInt(n, 10, 32)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return int32(offset), nil
+}
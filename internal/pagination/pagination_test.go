@@ -0,0 +1,126 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC),
+		ID:        uuid.New(),
+	}
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "missing separator", cursor: "bm9zZXBhcmF0b3I"},
+		{name: "truncated", cursor: Cursor{CreatedAt: time.Now()}.Encode()[:20]},
+		{name: "bad uuid", cursor: base64.RawURLEncoding.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano) + "|not-a-uuid"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   string
+		want    int32
+		wantErr bool
+	}{
+		{name: "default", limit: "", want: DefaultLimit},
+		{name: "valid", limit: "50", want: 50},
+		{name: "zero", limit: "0", wantErr: true},
+		{name: "too large", limit: "101", wantErr: true},
+		{name: "not a number", limit: "ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimit(tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetRoundTrip(t *testing.T) {
+	got, err := DecodeOffset(EncodeOffset(40))
+	if err != nil {
+		t.Fatalf("DecodeOffset() error = %v", err)
+	}
+	if got != 40 {
+		t.Errorf("got %d, want %d", got, 40)
+	}
+
+}
+
+func TestDecodeOffsetInvalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "keyset cursor", cursor: Cursor{CreatedAt: time.Now(), ID: uuid.New()}.Encode()},
+		{name: "negative", cursor: encode("offset|-1")},
+		{name: "wraps to zero as int32", cursor: encode("offset|4294967296")},
+		{name: "above int32", cursor: encode("offset|2147483648")},
+		{name: "not a number", cursor: encode("offset|ten")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeOffset(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeOffset() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: uuid.New()}
+
+	limit, got, err := Parse(url.Values{"limit": {"5"}, "cursor": {cursor.Encode()}})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if limit != 5 || got == nil || *got != cursor {
+		t.Errorf("got (%d, %+v), want (5, %+v)", limit, got, cursor)
+	}
+
+	limit, got, err = Parse(url.Values{})
+	if err != nil || limit != DefaultLimit || got != nil {
+		t.Errorf("Parse() = (%d, %+v, %v), want (%d, nil, nil)", limit, got, err, DefaultLimit)
+	}
+
+	createdAt, id := got.QueryArgs()
+	if createdAt.Valid || id.Valid {
+		t.Errorf("QueryArgs() on a nil cursor should return NULL arguments")
+	}
+}
@@ -0,0 +1,125 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrSlowConsumer = errors.New("subscriber fell too far behind")
+
+type Event struct {
+	ID    uint64
+	Topic string
+	Data  []byte
+}
+
+// Hub fans published events out to in-process subscribers and keeps a bounded
+// history so that reconnecting clients can resume where they left off.
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event
+	historySize int
+	subs        map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	hub    *Hub
+	events chan Event
+	match  func(topic string) bool
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		historySize: historySize,
+		subs:        map[*Subscription]struct{}{},
+	}
+}
+
+func (h *Hub) Publish(topic string, data []byte) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event := Event{ID: h.lastID, Topic: topic, Data: data}
+	h.history = append(h.history, event)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subs {
+		if !sub.match(topic) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Never block publishers on a slow reader, drop it instead and
+			// let the client reconnect and resume from history.
+			delete(h.subs, sub)
+			sub.close(ErrSlowConsumer)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a subscriber for topics accepted by match. Events newer
+// than lastEventID that are still in the history are returned as a backlog,
+// everything published afterwards is delivered on the subscription.
+func (h *Hub) Subscribe(lastEventID uint64, bufferSize int, match func(topic string) bool) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Event
+	if lastEventID > 0 {
+		for _, event := range h.history {
+			if event.ID > lastEventID && match(event.Topic) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		hub:    h,
+		events: make(chan Event, bufferSize),
+		match:  match,
+		done:   make(chan struct{}),
+	}
+	h.subs[sub] = struct{}{}
+	return sub, backlog
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed once the subscription ends, Err reports why.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	delete(s.hub.subs, s)
+	s.hub.mu.Unlock()
+	s.close(nil)
+}
+
+func (s *Subscription) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
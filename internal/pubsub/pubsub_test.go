@@ -0,0 +1,76 @@
+package pubsub
+
+import (
+	"strings"
+	"testing"
+)
+
+func matchPrefix(prefix string) func(string) bool {
+	return func(topic string) bool { return strings.HasPrefix(topic, prefix) }
+}
+
+func TestPublishDeliversMatchingEvents(t *testing.T) {
+	hub := NewHub(10)
+	sub, backlog := hub.Subscribe(0, 10, matchPrefix("chirps:"))
+	defer sub.Close()
+	if len(backlog) != 0 {
+		t.Fatalf("got backlog of %d events, want none", len(backlog))
+	}
+
+	hub.Publish("notifications:a", []byte("skip"))
+	hub.Publish("chirps:a", []byte("hello"))
+
+	event := <-sub.Events()
+	if event.Topic != "chirps:a" || string(event.Data) != "hello" || event.ID != 2 {
+		t.Errorf("got %+v", event)
+	}
+}
+
+func TestSubscribeReplaysHistory(t *testing.T) {
+	hub := NewHub(3)
+	for _, topic := range []string{"chirps:a", "chirps:b", "other", "chirps:c", "chirps:d"} {
+		hub.Publish(topic, nil)
+	}
+
+	sub, backlog := hub.Subscribe(3, 10, matchPrefix("chirps:"))
+	defer sub.Close()
+
+	var ids []uint64
+	for _, event := range backlog {
+		ids = append(ids, event.ID)
+	}
+	if len(ids) != 2 || ids[0] != 4 || ids[1] != 5 {
+		t.Errorf("got backlog ids %v, want [4 5]", ids)
+	}
+}
+
+func TestSlowConsumerIsDropped(t *testing.T) {
+	hub := NewHub(10)
+	sub, _ := hub.Subscribe(0, 1, matchPrefix(""))
+
+	hub.Publish("a", nil)
+	hub.Publish("a", nil)
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("expected subscription to be closed")
+	}
+	if sub.Err() != ErrSlowConsumer {
+		t.Errorf("got err %v, want %v", sub.Err(), ErrSlowConsumer)
+	}
+}
+
+func TestCloseRemovesSubscription(t *testing.T) {
+	hub := NewHub(10)
+	sub, _ := hub.Subscribe(0, 1, matchPrefix(""))
+	sub.Close()
+
+	hub.Publish("a", nil)
+	if sub.Err() != nil {
+		t.Errorf("got err %v, want nil", sub.Err())
+	}
+	if len(hub.subs) != 0 {
+		t.Errorf("got %d subscriptions, want 0", len(hub.subs))
+	}
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptyQuery = errors.New("search query must contain at least one word")
+
+// ToTSQuery converts a user supplied search string into to_tsquery syntax.
+// Double quoted text becomes a phrase query and a trailing * turns a word into
+// a prefix query, every other word is required to match.
+func ToTSQuery(q string) (string, error) {
+	var clauses []string
+	for i, part := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			if words := lexemes(part); len(words) > 0 {
+				clauses = append(clauses, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+		for field := range strings.FieldsSeq(part) {
+			prefix := strings.HasSuffix(field, "*")
+			words := lexemes(field)
+			if len(words) == 0 {
+				continue
+			}
+			if prefix {
+				words[len(words)-1] += ":*"
+			}
+			if len(words) == 1 {
+				clauses = append(clauses, words[0])
+				continue
+			}
+			clauses = append(clauses, "("+strings.Join(words, " <-> ")+")")
+		}
+	}
+	if len(clauses) == 0 {
+		return "", ErrEmptyQuery
+	}
+	return strings.Join(clauses, " & "), nil
+}
+
+// lexemes strips everything that could be interpreted as a tsquery operator.
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,34 @@
+package search
+
+import "testing"
+
+func TestToTSQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "single word", query: "Chirpy", want: "chirpy"},
+		{name: "multiple words", query: "go server", want: "go & server"},
+		{name: "prefix", query: "mast*", want: "mast:*"},
+		{name: "phrase", query: `"better than chirpy"`, want: "(better <-> than <-> chirpy)"},
+		{name: "phrase and words", query: `bed "go to" soon*`, want: "bed & (go <-> to) & soon:*"},
+		{name: "operators are stripped", query: "a&b | !c", want: "(a <-> b) & c"},
+		{name: "unterminated quote", query: `"hello world`, want: "(hello <-> world)"},
+		{name: "only punctuation", query: `!!! "" *`, wantErr: true},
+		{name: "empty", query: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToTSQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToTSQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
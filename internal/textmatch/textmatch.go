@@ -0,0 +1,182 @@
+// Package textmatch finds words and phrases in free text. Matching ignores
+// case, accents, compatibility forms such as full-width letters, and the
+// punctuation and spacing between words.
+package textmatch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rule is a word or phrase to look for. Whole-word rules only match complete
+// words, so "ass" doesn't match "class", other rules match anywhere.
+type Rule struct {
+	Phrase    string
+	WholeWord bool
+}
+
+// Range is a match as byte offsets into the original text.
+type Range struct {
+	Start int
+	End   int
+}
+
+type Matcher struct {
+	rules []Rule
+}
+
+// New compiles rules into a Matcher. Rules without any letters, digits or
+// symbols can never match and are dropped.
+func New(rules []Rule) *Matcher {
+	m := &Matcher{}
+	for _, rule := range rules {
+		if phrase := Normalize(rule.Phrase); phrase != "" {
+			m.rules = append(m.rules, Rule{Phrase: phrase, WholeWord: rule.WholeWord})
+		}
+	}
+	return m
+}
+
+// Normalize returns the form text is compared in: lowercased words without
+// accents, separated by single spaces.
+func Normalize(s string) string {
+	return fold(s).text
+}
+
+func (m *Matcher) Match(text string) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+	f := fold(text)
+	for _, rule := range m.rules {
+		if len(f.find(rule, true)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAll returns the sorted, non-overlapping ranges of text matched by any
+// rule.
+func (m *Matcher) FindAll(text string) []Range {
+	if len(m.rules) == 0 {
+		return nil
+	}
+	f := fold(text)
+	var ranges []Range
+	for _, rule := range m.rules {
+		ranges = append(ranges, f.find(rule, false)...)
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// folded is text in normalized form. start and end map every byte of the
+// normalized text back to the original rune it came from.
+type folded struct {
+	text  string
+	start []int
+	end   []int
+}
+
+func fold(s string) folded {
+	var b strings.Builder
+	var start, end []int
+	separate := false
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		next := i + size
+
+		if unicode.Is(unicode.Mn, r) {
+			// Combining marks belong to the rune before them.
+			if len(end) > 0 && end[len(end)-1] == i {
+				for j := len(end) - 1; j >= 0 && end[j] == i; j-- {
+					end[j] = next
+				}
+			}
+			i = next
+			continue
+		}
+
+		symbol := unicode.Is(unicode.So, r)
+		if !symbol && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separate = b.Len() > 0
+			i = next
+			continue
+		}
+		if b.Len() > 0 && (separate || symbol || isSymbolEnd(b.String())) {
+			b.WriteByte(' ')
+			start = append(start, i)
+			end = append(end, i)
+		}
+		separate = false
+
+		folded := foldRune(r)
+		b.WriteString(folded)
+		for range len(folded) {
+			start = append(start, i)
+			end = append(end, next)
+		}
+		i = next
+	}
+	return folded{text: b.String(), start: start, end: end}
+}
+
+// foldRune decomposes r, drops any accents and lowercases what is left.
+func foldRune(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, d) {
+			b.WriteRune(unicode.ToLower(d))
+		}
+	}
+	return b.String()
+}
+
+// isSymbolEnd reports whether s ends in a symbol, symbols are words of their
+// own so "🍕🍕" is two words.
+func isSymbolEnd(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.Is(unicode.So, r)
+}
+
+func (f folded) find(rule Rule, first bool) []Range {
+	var ranges []Range
+	for offset := 0; offset < len(f.text); {
+		i := strings.Index(f.text[offset:], rule.Phrase)
+		if i < 0 {
+			break
+		}
+		s, e := offset+i, offset+i+len(rule.Phrase)
+		offset = s + 1
+		if rule.WholeWord && !f.isBoundary(s, e) {
+			continue
+		}
+		ranges = append(ranges, Range{Start: f.start[s], End: f.end[e-1]})
+		if first {
+			break
+		}
+	}
+	return ranges
+}
+
+func (f folded) isBoundary(s, e int) bool {
+	return (s == 0 || f.text[s-1] == ' ') && (e == len(f.text) || f.text[e] == ' ')
+}
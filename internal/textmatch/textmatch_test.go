@@ -0,0 +1,88 @@
+package textmatch
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Hello, World!", want: "hello world"},
+		{in: "  multiple   spaces\tand\nlines ", want: "multiple spaces and lines"},
+		{in: "F\u00f3rnax", want: "fornax"},
+		{in: "Fo\u0301rnax", want: "fornax"},
+		{in: "ＦＵＬＬ width", want: "full width"},
+		{in: "good-bye", want: "good bye"},
+		{in: "pizza🍕🍕", want: "pizza 🍕 🍕"},
+		{in: "?!.", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		text string
+		want bool
+	}{
+		{name: "whole word", rule: Rule{Phrase: "kerfuffle", WholeWord: true}, text: "what a Kerfuffle!", want: true},
+		{name: "whole word inside word", rule: Rule{Phrase: "ass", WholeWord: true}, text: "first class", want: false},
+		{name: "substring inside word", rule: Rule{Phrase: "ass", WholeWord: false}, text: "first class", want: true},
+		{name: "punctuation", rule: Rule{Phrase: "sharbert", WholeWord: true}, text: "sharbert, again", want: true},
+		{name: "accents", rule: Rule{Phrase: "fornax", WholeWord: true}, text: "FÓRNAX", want: true},
+		{name: "phrase across punctuation", rule: Rule{Phrase: "good bye", WholeWord: true}, text: "Good-bye!", want: true},
+		{name: "phrase needs every word", rule: Rule{Phrase: "good bye", WholeWord: true}, text: "good day", want: false},
+		{name: "symbol", rule: Rule{Phrase: "🍕", WholeWord: true}, text: "lunch🍕", want: true},
+		{name: "empty rule", rule: Rule{Phrase: "!!", WholeWord: false}, text: "!!", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New([]Rule{tt.rule}).Match(tt.text); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	m := New([]Rule{
+		{Phrase: "kerfuffle", WholeWord: true},
+		{Phrase: "sharb", WholeWord: false},
+		{Phrase: "fornax", WholeWord: true},
+	})
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "no match here", want: nil},
+		{text: "a Kerfuffle!", want: []string{"Kerfuffle"}},
+		{text: "sharbert, kerfuffle", want: []string{"sharb", "kerfuffle"}},
+		{text: "F\u00f3rnax.", want: []string{"F\u00f3rnax"}},
+		{text: "Fo\u0301rnax.", want: []string{"Fo\u0301rnax"}},
+		{text: "ｆｏｒｎａｘ", want: []string{"ｆｏｒｎａｘ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []string
+			for _, r := range m.FindAll(tt.text) {
+				got = append(got, tt.text[r.Start:r.End])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FindAll(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
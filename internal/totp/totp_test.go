@@ -0,0 +1,129 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// The RFC 6238 test secret "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current", code: code(step), wantStep: step, wantOK: true},
+		{name: "previous period", code: code(step - 1), wantStep: step - 1, wantOK: true},
+		{name: "next period", code: code(step + 1), wantStep: step + 1, wantOK: true},
+		{name: "too old", code: code(step - 2)},
+		{name: "too new", code: code(step + 2)},
+		{name: "already used", code: code(step), lastStep: step},
+		{name: "earlier step used", code: code(step), lastStep: step - 1, wantStep: step, wantOK: true},
+		{name: "wrong length", code: "12345"},
+		{name: "surrounding spaces", code: " " + code(step) + " ", wantStep: step, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK, err := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("len(secret) = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("Code() with generated secret error = %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := URI("Chirpy", "walt@breakingbad.com", "ABC")
+	want := "otpauth://totp/Chirpy:walt@breakingbad.com?algorithm=SHA1&digits=6&issuer=Chirpy&period=30&secret=ABC"
+	if got != want {
+		t.Errorf("URI() = %q, want %q", got, want)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not formatted like abcde-fghij", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "abcde-fghij", want: "abcdefghij"},
+		{in: " ABCDE FGHIJ ", want: "abcdefghij"},
+		{in: "abcdefghij", want: "abcdefghij"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.in); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Package websocket implements the server side of RFC 6455, just enough for
+// JSON messaging: text and binary messages, fragmentation and control frames.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseTryAgainLater   = 1013
+)
+
+var (
+	ErrBadHandshake    = errors.New("websocket: bad handshake")
+	ErrMessageTooBig   = errors.New("websocket: message too big")
+	ErrProtocol        = errors.New("websocket: protocol error")
+	ErrUnmaskedFrame   = errors.New("websocket: client frames must be masked")
+	ErrControlTooLarge = errors.New("websocket: control frame payload too large")
+)
+
+// CloseError is returned by ReadMessage once the peer sent a close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+type Conn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	writeMu        sync.Mutex
+	maxMessageSize int64
+	pongHandler    func()
+}
+
+// Upgrade performs the opening handshake and takes over the connection.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessageSize int64) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, ErrBadHandshake
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, ErrBadHandshake
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// The handshake must not be answered while unread client data is buffered.
+	if rw.Reader.Buffered() > 0 {
+		netConn.Close()
+		return nil, ErrBadHandshake
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	if _, err := netConn.Write([]byte(handshake)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return newConn(netConn, maxMessageSize), nil
+}
+
+func newConn(netConn net.Conn, maxMessageSize int64) *Conn {
+	return &Conn{
+		conn:           netConn,
+		br:             bufio.NewReader(netConn),
+		maxMessageSize: maxMessageSize,
+	}
+}
+
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerContains(header http.Header, name, value string) bool {
+	for _, v := range header.Values(name) {
+		for token := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPongHandler registers a callback that runs whenever a pong arrives.
+// It is invoked from ReadMessage, so it must not block.
+func (c *Conn) SetPongHandler(h func()) {
+	c.pongHandler = h
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered and
+// pongs are handed to the pong handler while waiting for it.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var opcode int
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case OpPing:
+			if err := c.WriteControl(OpPong, payload, time.Now().Add(5*time.Second)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			if c.pongHandler != nil {
+				c.pongHandler()
+			}
+			continue
+		case OpClose:
+			closeErr := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.WriteClose(CloseNormal, "", time.Now().Add(5*time.Second))
+			return 0, nil, closeErr
+		case OpText, OpBinary:
+			if opcode != 0 {
+				return 0, nil, ErrProtocol
+			}
+			opcode = op
+		case OpContinuation:
+			if opcode == 0 {
+				return 0, nil, ErrProtocol
+			}
+		default:
+			return 0, nil, ErrProtocol
+		}
+
+		if int64(len(message)+len(payload)) > c.maxMessageSize {
+			c.WriteClose(CloseMessageTooBig, "message too big", time.Now().Add(5*time.Second))
+			return 0, nil, ErrMessageTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, ErrProtocol
+	}
+	opcode := int(header[0] & 0x0F)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7F)
+
+	if !masked {
+		return false, 0, nil, ErrUnmaskedFrame
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if opcode >= OpClose && (length > 125 || !fin) {
+		return false, 0, nil, ErrControlTooLarge
+	}
+	if length < 0 || length > c.maxMessageSize {
+		c.WriteClose(CloseMessageTooBig, "message too big", time.Now().Add(5*time.Second))
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage sends data as a single unfragmented frame.
+func (c *Conn) WriteMessage(opcode int, data []byte, deadline time.Time) error {
+	return c.writeFrame(opcode, data, deadline)
+}
+
+func (c *Conn) WriteControl(opcode int, data []byte, deadline time.Time) error {
+	if len(data) > 125 {
+		return ErrControlTooLarge
+	}
+	return c.writeFrame(opcode, data, deadline)
+}
+
+func (c *Conn) WriteClose(code int, reason string, deadline time.Time) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return c.WriteControl(OpClose, payload, deadline)
+}
+
+func (c *Conn) writeFrame(opcode int, data []byte, deadline time.Time) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	frame := make([]byte, 0, len(data)+10)
+	frame = append(frame, 0x80|byte(opcode))
+	switch {
+	case len(data) <= 125:
+		frame = append(frame, byte(len(data)))
+	case len(data) <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(data)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(data)))
+	}
+	frame = append(frame, data...)
+
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// clientFrame builds a masked frame the way a browser would send it.
+func clientFrame(fin bool, opcode int, payload []byte) []byte {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example handshake from RFC 6455 section 1.3.
+	got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	if want := "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 1024)
+
+	go func() {
+		client.Write(clientFrame(false, OpText, []byte("hel")))
+		client.Write(clientFrame(true, OpContinuation, []byte("lo")))
+	}()
+
+	opcode, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if opcode != OpText || string(message) != "hello" {
+		t.Errorf("got (%d, %q), want (%d, %q)", opcode, message, OpText, "hello")
+	}
+}
+
+func TestReadMessageAnswersPing(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 1024)
+
+	pong := make(chan []byte, 1)
+	go func() {
+		client.Write(clientFrame(true, OpPing, []byte("hi")))
+		frame := make([]byte, 4)
+		io.ReadFull(client, frame)
+		pong <- frame
+		client.Write(clientFrame(true, OpText, []byte("after")))
+	}()
+
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if string(message) != "after" {
+		t.Errorf("got %q, want %q", message, "after")
+	}
+	if frame := <-pong; frame[0] != 0x80|OpPong || string(frame[2:]) != "hi" {
+		t.Errorf("got pong frame %v", frame)
+	}
+}
+
+func TestReadMessageRejectsUnmaskedFrames(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 1024)
+
+	go client.Write([]byte{0x80 | OpText, 2, 'h', 'i'})
+
+	if _, _, err := conn.ReadMessage(); err != ErrUnmaskedFrame {
+		t.Errorf("got err %v, want %v", err, ErrUnmaskedFrame)
+	}
+}
+
+func TestReadMessageTooBig(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 4)
+
+	go func() {
+		client.Write(clientFrame(true, OpText, []byte("too long")))
+		io.Copy(io.Discard, client)
+	}()
+
+	if _, _, err := conn.ReadMessage(); err != ErrMessageTooBig {
+		t.Errorf("got err %v, want %v", err, ErrMessageTooBig)
+	}
+}
+
+func TestReadMessageClose(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 1024)
+
+	go func() {
+		payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+		client.Write(clientFrame(true, OpClose, append(payload, "bye"...)))
+		io.Copy(io.Discard, client)
+	}()
+
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway || closeErr.Reason != "bye" {
+		t.Errorf("got err %v, want close error %d", err, CloseGoingAway)
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn := newConn(server, 1024)
+
+	payload := make([]byte, 300)
+	go conn.WriteMessage(OpBinary, payload, time.Now().Add(time.Second))
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(client, header); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != 0x80|OpBinary || header[1] != 126 || binary.BigEndian.Uint16(header[2:]) != 300 {
+		t.Errorf("got header %v", header)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/markoc1120/go_server/internal/mailer"
+)
+
+const mailSendTimeout = 30 * time.Second
+
+// sendEmail delivers msg in the background so slow mail servers don't hold
+// up the request that triggered it.
+func (cfg *apiConfig) sendEmail(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := cfg.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Couldn't send %q email to %s: %s", msg.Subject, msg.To, err)
+		}
+	}()
+}
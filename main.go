@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -8,15 +9,29 @@ import (
 	"sync/atomic"
 
 	_ "github.com/lib/pq"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/auth"
 	"github.com/markoc1120/go_server/internal/config"
 	"github.com/markoc1120/go_server/internal/database"
+	"github.com/markoc1120/go_server/internal/encryption"
+	"github.com/markoc1120/go_server/internal/mailer"
 	"github.com/markoc1120/go_server/internal/middleware"
+	"github.com/markoc1120/go_server/internal/moderation"
+	"github.com/markoc1120/go_server/internal/notifications"
+	"github.com/markoc1120/go_server/internal/pubsub"
 )
 
 type apiConfig struct {
 	fileServerHits atomic.Int32
 	db             *database.Queries
+	dbConn         *sql.DB
 	config         *config.Config
+	events         *pubsub.Hub
+	notifications  *notifications.Service
+	profanity      *moderation.Filter
+	audit          *audit.Logger
+	mailer         mailer.Mailer // nil when MAILER is none
+	totpBox        *encryption.Box
 }
 
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
@@ -32,6 +47,18 @@ func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
 `, cfg.fileServerHits.Load())))
 }
 
+// newMailer returns nil when sending email is disabled.
+func newMailer(cfg *config.Config) mailer.Mailer {
+	switch cfg.Mailer {
+	case config.MailerSMTP:
+		return mailer.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	case config.MailerOutbox:
+		return mailer.NewOutbox(cfg.OutboxDir, cfg.MailFrom)
+	}
+	log.Print("MAILER is none, password reset and email verification are disabled")
+	return nil
+}
+
 func main() {
 	const filepathRoot = "."
 
@@ -46,10 +73,29 @@ func main() {
 	}
 	dbQueries := database.New(dbConn)
 
+	profanity := moderation.NewFilter(dbQueries, cfg.ProfanityReplacement)
+	if err := profanity.Reload(context.Background()); err != nil {
+		log.Fatalf("Failed to load profane words: %s", err)
+	}
+	go profanity.Watch(context.Background(), cfg.ProfanityReloadInterval)
+
+	totpBox, err := encryption.New(cfg.EncryptionKey, "chirpy totp secrets")
+	if err != nil {
+		log.Fatalf("Failed to set up encryption: %s", err)
+	}
+
+	events := pubsub.NewHub(1000)
 	apiCfg := apiConfig{
 		fileServerHits: atomic.Int32{},
 		db:             dbQueries,
+		dbConn:         dbConn,
 		config:         cfg,
+		events:         events,
+		notifications:  notifications.NewService(dbQueries, events),
+		profanity:      profanity,
+		audit:          audit.NewLogger(dbQueries),
+		mailer:         newMailer(cfg),
+		totpBox:        totpBox,
 	}
 
 	appHandler := http.FileServer(http.Dir(filepathRoot))
@@ -62,28 +108,98 @@ func main() {
 	// User endpoints
 	mux.HandleFunc("POST /api/users", apiCfg.handlerUsersCreate)
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerUsersUpdate)
+	mux.HandleFunc("GET /api/users/me/mentions", apiCfg.handlerMentionsGet)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollowCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerFollowDelete)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerFollowersGet)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerFollowingGet)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handlerBlockCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handlerBlockDelete)
+	mux.HandleFunc("GET /api/users/me/blocks", apiCfg.handlerBlocksGet)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handlerMuteCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handlerMuteDelete)
+	mux.HandleFunc("GET /api/users/me/mutes", apiCfg.handlerMutesGet)
+	mux.HandleFunc("POST /api/users/me/verification", apiCfg.handlerEmailVerificationResend)
+	mux.HandleFunc("POST /api/users/me/2fa/totp", apiCfg.handlerTOTPEnroll)
+	mux.HandleFunc("POST /api/users/me/2fa/totp/confirm", apiCfg.handlerTOTPConfirm)
+	mux.HandleFunc("POST /api/users/me/2fa/totp/disable", apiCfg.handlerTOTPDisable)
+	mux.HandleFunc("POST /api/users/me/2fa/recovery_codes", apiCfg.handlerRecoveryCodesRegenerate)
+	mux.HandleFunc("POST /api/users/me/muted_words", apiCfg.handlerMutedWordsCreate)
+	mux.HandleFunc("GET /api/users/me/muted_words", apiCfg.handlerMutedWordsGet)
+	mux.HandleFunc("DELETE /api/users/me/muted_words/{mutedWordID}", apiCfg.handlerMutedWordsDelete)
 
 	// Auth endpoints
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
+	mux.HandleFunc("POST /api/login/2fa", apiCfg.handlerLoginTwoFactor)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.handlerPasswordForgot)
+	mux.HandleFunc("POST /api/password/reset", apiCfg.handlerPasswordReset)
+	mux.HandleFunc("POST /api/email/verify", apiCfg.handlerEmailVerify)
+	mux.HandleFunc("GET /api/sessions", apiCfg.handlerSessionsGet)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", apiCfg.handlerSessionDelete)
+	mux.HandleFunc("POST /api/sessions/revoke-all", apiCfg.handlerSessionsRevokeAll)
 
 	// Chirp endpoints
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerChirpsCreate)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerChirpsRetrieve)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerChirpsSearch)
+	mux.HandleFunc("GET /api/chirps/stream", apiCfg.handlerChirpsStream)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerChirpsGet)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerChirpsUpdate)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.handlerChirpRevisionsGet)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerChirpsThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/likes", apiCfg.handlerChirpLikesCreate)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", apiCfg.handlerChirpLikesDelete)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirps", apiCfg.handlerRechirpsCreate)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirps", apiCfg.handlerRechirpsDelete)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/quotes", apiCfg.handlerQuotesCreate)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", apiCfg.handlerReportsCreate)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerChirpsDelete)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerTimeline)
+
+	// Hashtag endpoints
+	mux.HandleFunc("GET /api/hashtags/trending", apiCfg.handlerHashtagsTrending)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerHashtagChirps)
+
+	// Notification endpoints
+	mux.HandleFunc("GET /api/notifications", apiCfg.handlerNotificationsGet)
+	mux.HandleFunc("GET /api/notifications/unread_count", apiCfg.handlerNotificationsUnreadCount)
+	mux.HandleFunc("POST /api/notifications/read", apiCfg.handlerNotificationsRead)
+
+	// Direct message endpoints
+	mux.HandleFunc("POST /api/conversations", apiCfg.handlerConversationsCreate)
+	mux.HandleFunc("GET /api/conversations", apiCfg.handlerConversationsList)
+	mux.HandleFunc("GET /api/conversations/{conversationID}/messages", apiCfg.handlerMessagesList)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/messages", apiCfg.handlerMessagesCreate)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/read", apiCfg.handlerConversationRead)
+
+	// Realtime endpoints
+	mux.HandleFunc("GET /api/ws", apiCfg.handlerWebSocket)
 
 	// Webhook endpoints
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerPolkaWebhooks)
 
 	// Admin endpoints
-	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
-	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
+	requirePermission := func(permission auth.Permission, handler http.HandlerFunc) http.Handler {
+		return middleware.RequirePermission(cfg.Secret, apiCfg.currentRole, permission)(handler)
+	}
+	mux.Handle("GET /admin/metrics", requirePermission(auth.PermissionViewMetrics, apiCfg.handlerMetrics))
+	mux.Handle("POST /admin/reset", requirePermission(auth.PermissionResetData, apiCfg.handlerReset))
+	mux.Handle("GET /admin/moderation/words", requirePermission(auth.PermissionManageWords, apiCfg.handlerProfaneWordsGet))
+	mux.Handle("POST /admin/moderation/words", requirePermission(auth.PermissionManageWords, apiCfg.handlerProfaneWordsCreate))
+	mux.Handle("DELETE /admin/moderation/words/{wordID}", requirePermission(auth.PermissionManageWords, apiCfg.handlerProfaneWordsDelete))
+	mux.Handle("GET /admin/reports", requirePermission(auth.PermissionReviewReports, apiCfg.handlerReportsGet))
+	mux.Handle("POST /admin/reports/{reportID}/actions", requirePermission(auth.PermissionReviewReports, apiCfg.handlerReportAction))
+	mux.Handle("PUT /admin/users/{userID}/role", requirePermission(auth.PermissionManageRoles, apiCfg.handlerUserRoleUpdate))
+	mux.Handle("GET /admin/users/{userID}/restrictions", requirePermission(auth.PermissionRestrictUsers, apiCfg.handlerUserRestrictionsGet))
+	mux.Handle("POST /admin/users/{userID}/restrictions", requirePermission(auth.PermissionRestrictUsers, apiCfg.handlerUserRestrictionsCreate))
+	mux.Handle("POST /admin/users/{userID}/restrictions/lift", requirePermission(auth.PermissionRestrictUsers, apiCfg.handlerUserRestrictionsLift))
+	mux.Handle("GET /admin/audit", requirePermission(auth.PermissionViewAudit, apiCfg.handlerAuditEventsGet))
 
 	server := http.Server{
 		Addr:    ":" + cfg.Port,
-		Handler: mux,
+		Handler: middleware.RequestID(middleware.RejectSuspendedWrites(cfg.Secret, apiCfg.suspension)(mux)),
 	}
 
 	log.Printf("Server starting on port %s", cfg.Port)
@@ -3,6 +3,9 @@ package main
 import (
 	"net/http"
 
+	"github.com/google/uuid"
+	"github.com/markoc1120/go_server/internal/audit"
+	"github.com/markoc1120/go_server/internal/middleware"
 	"github.com/markoc1120/go_server/internal/response"
 )
 
@@ -11,10 +14,44 @@ func (cfg *apiConfig) handlerReset(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, http.StatusForbidden, "You can't do this, reset is only allowed in dev environment.", nil)
 		return
 	}
-	err := cfg.db.DeleteUsers(r.Context())
+	claims, ok := middleware.Claims(r.Context())
+	if !ok {
+		response.WithError(w, http.StatusUnauthorized, "Couldn't find token", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The calling admin is kept so the reset can be repeated without
+	// bootstrapping a new admin, only their chirps are removed.
+	err = qtx.DeleteOtherUsers(r.Context(), claims.UserID)
 	if err != nil {
 		response.WithError(w, http.StatusInternalServerError, "Couldn't delete all users.", err)
 		return
 	}
+	err = qtx.DeleteUserChirps(r.Context(), claims.UserID)
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't delete chirps.", err)
+		return
+	}
+	err = cfg.audit.Record(r.Context(), qtx, r, audit.Event{
+		Action:  audit.ActionDataReset,
+		ActorID: uuid.NullUUID{UUID: claims.UserID, Valid: true},
+	})
+	if err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't record audit event", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		response.WithError(w, http.StatusInternalServerError, "Couldn't commit transaction", err)
+		return
+	}
 	response.WithJSON(w, http.StatusOK, nil)
 }
@@ -0,0 +1,19 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (id, action, actor_id, target_id, ip, user_agent, request_id, details, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, NOW());
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id')::uuid)
+AND (sqlc.narg('target_id')::uuid IS NULL OR target_id = sqlc.narg('target_id')::uuid)
+AND (sqlc.narg('ip')::text IS NULL OR ip = sqlc.narg('ip')::text)
+AND (sqlc.narg('request_id')::text IS NULL OR request_id = sqlc.narg('request_id')::text)
+AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since')::timestamp)
+AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until')::timestamp)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
@@ -0,0 +1,54 @@
+-- name: CreateBlock :execrows
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: ListBlocks :many
+SELECT blocked_id, created_at FROM blocks
+WHERE blocker_id = @user_id
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, blocked_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, blocked_id DESC
+LIMIT @page_size;
+
+-- name: IsBlockedBetween :one
+SELECT is_blocked_between(@user_id::uuid, @other_id::uuid);
+
+-- name: IsHiddenFrom :one
+SELECT is_hidden_from(@viewer_id::uuid, @author_id::uuid);
+
+-- name: IsHiddenFromAny :one
+SELECT COALESCE(bool_or(is_hidden_from(@viewer_id::uuid, author_id)), FALSE)::boolean
+FROM unnest(@author_ids::uuid[]) AS author_id;
+
+-- name: ListBlockedAmong :many
+SELECT blocked_id AS user_id FROM blocks
+WHERE blocker_id = @user_id AND blocked_id = ANY(@user_ids::uuid[])
+UNION
+SELECT blocker_id AS user_id FROM blocks
+WHERE blocked_id = @user_id AND blocker_id = ANY(@user_ids::uuid[]);
+
+-- name: CreateMute :execrows
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM mutes
+WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: ListMutes :many
+SELECT muted_id, created_at FROM mutes
+WHERE muter_id = @user_id
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, muted_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, muted_id DESC
+LIMIT @page_size;
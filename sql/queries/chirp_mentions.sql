@@ -0,0 +1,22 @@
+-- name: AddChirpMentions :many
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT @chirp_id::uuid, user_id, @created_at::timestamp
+FROM unnest(@user_ids::uuid[]) AS user_id
+ON CONFLICT DO NOTHING
+RETURNING user_id;
+
+-- name: DeleteStaleChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = @chirp_id AND NOT (user_id = ANY(@user_ids::uuid[]));
+
+-- name: ListMentionedChirps :many
+SELECT chirps.* FROM chirps
+INNER JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+WHERE chirp_mentions.user_id = @user_id
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+AND NOT is_hidden_from(@user_id, chirps.user_id)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @page_size;
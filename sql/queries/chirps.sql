@@ -1,26 +1,149 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
--- name: GetChirps :many
+-- name: ListChirpsAsc :many
 SELECT * FROM chirps
-ORDER BY created_at;
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+AND NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, user_id)
+ORDER BY created_at ASC, id ASC
+LIMIT @page_size;
 
--- name: GetChirpsByUserID :many
+-- name: ListChirpsDesc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at;
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+AND NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, user_id)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
+
+-- name: CreateChirpReference :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, referenced_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: ListTimeline :many
+SELECT * FROM chirps
+WHERE (
+    user_id = @user_id
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = @user_id)
+)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+AND NOT is_hidden_from(@user_id, user_id)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
 
 -- name: GetChirp :one
 SELECT * FROM chirps
 WHERE id = $1;
 
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(@ids::uuid[])
+AND NOT is_blocked_between(sqlc.narg('viewer_id')::uuid, user_id)
+AND NOT (is_restricted(user_id, 'shadow_ban') AND user_id IS DISTINCT FROM sqlc.narg('viewer_id')::uuid);
+
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 AND referenced_chirp_id = $2 AND kind = 'rechirp';
+
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: IncrementChirpLikeCount :one
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id = $1
+RETURNING like_count;
+
+-- name: DecrementChirpLikeCount :one
+UPDATE chirps
+SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1
+RETURNING like_count;
+
 -- name: DeleteChirp :exec
 DELETE FROM chirps WHERE id = $1;
+
+-- name: DeleteRechirpsOf :exec
+DELETE FROM chirps
+WHERE referenced_chirp_id = $1 AND kind = 'rechirp';
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.search_vector, to_tsquery('english', @query)) AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', @query)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+AND NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT @page_size OFFSET @page_offset;
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to, 1 AS depth
+    FROM chirps child
+    INNER JOIN chirps parent ON parent.id = child.in_reply_to
+    WHERE child.id = @chirp_id::uuid
+    UNION ALL
+    SELECT chirps.id, chirps.in_reply_to, ancestors.depth + 1
+    FROM chirps
+    INNER JOIN ancestors ON chirps.id = ancestors.in_reply_to
+    WHERE ancestors.depth < @max_depth::int
+)
+SELECT sqlc.embed(chirps), ancestors.depth
+FROM ancestors
+INNER JOIN chirps ON chirps.id = ancestors.id
+WHERE NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpDescendants :many
+WITH RECURSIVE replies AS (
+    SELECT chirps.id, 1 AS depth
+    FROM chirps
+    WHERE chirps.in_reply_to = @chirp_id::uuid
+    UNION ALL
+    SELECT chirps.id, replies.depth + 1
+    FROM chirps
+    INNER JOIN replies ON chirps.in_reply_to = replies.id
+    WHERE replies.depth < @max_depth::int
+)
+SELECT sqlc.embed(chirps), replies.depth
+FROM replies
+INNER JOIN chirps ON chirps.id = replies.id
+WHERE NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY replies.depth, chirps.created_at, chirps.id
+LIMIT @max_replies;
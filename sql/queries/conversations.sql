@@ -0,0 +1,51 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (id, direct_key, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, NOW(), NOW())
+ON CONFLICT (direct_key) DO NOTHING
+RETURNING *;
+
+-- name: AddConversationMembers :exec
+INSERT INTO conversation_members (conversation_id, user_id, joined_at)
+SELECT @conversation_id::uuid, user_id, NOW()
+FROM unnest(@user_ids::uuid[]) AS user_id;
+
+-- name: GetConversationByDirectKey :one
+SELECT * FROM conversations
+WHERE direct_key = $1;
+
+-- name: GetConversationMember :one
+SELECT * FROM conversation_members
+WHERE conversation_id = $1 AND user_id = $2;
+
+-- name: ListConversationMembers :many
+SELECT * FROM conversation_members
+WHERE conversation_id = ANY(@conversation_ids::uuid[])
+ORDER BY conversation_id, joined_at, user_id;
+
+-- name: ListConversations :many
+SELECT sqlc.embed(conversations), conversation_members.last_read_at,
+    (
+        SELECT COUNT(*) FROM messages
+        WHERE messages.conversation_id = conversations.id
+        AND messages.sender_id <> @user_id
+        AND (conversation_members.last_read_at IS NULL OR messages.created_at > conversation_members.last_read_at)
+    ) AS unread_count
+FROM conversations
+INNER JOIN conversation_members ON conversation_members.conversation_id = conversations.id
+WHERE conversation_members.user_id = @user_id
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (conversations.updated_at, conversations.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT @page_size;
+
+-- name: TouchConversation :exec
+UPDATE conversations
+SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkConversationRead :exec
+UPDATE conversation_members
+SET last_read_at = NOW()
+WHERE conversation_id = $1 AND user_id = $2;
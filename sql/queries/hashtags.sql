@@ -0,0 +1,40 @@
+-- name: AddChirpHashtags :exec
+WITH tags AS (
+    INSERT INTO hashtags (id, tag, created_at)
+    SELECT gen_random_uuid(), tag, NOW()
+    FROM unnest(@tags::text[]) AS tag
+    ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+    RETURNING id
+)
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+SELECT @chirp_id::uuid, tags.id, @created_at::timestamp
+FROM tags
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: ListHashtagChirps :many
+SELECT chirps.* FROM chirps
+INNER JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+INNER JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = @tag
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+AND NOT is_hidden_from(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @page_size;
+
+-- name: ListTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS chirp_count, COUNT(DISTINCT chirps.user_id) AS author_count
+FROM chirp_hashtags
+INNER JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+INNER JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.created_at >= @since
+AND NOT is_restricted(chirps.user_id, 'shadow_ban')
+GROUP BY hashtags.tag
+ORDER BY author_count DESC, chirp_count DESC, hashtags.tag
+LIMIT @page_size;
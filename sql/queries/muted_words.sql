@@ -0,0 +1,20 @@
+-- name: UpsertMutedWord :one
+INSERT INTO muted_words (id, user_id, phrase, whole_word, expires_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+ON CONFLICT (user_id, phrase) DO UPDATE
+SET whole_word = EXCLUDED.whole_word, expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: ListActiveMutedWords :many
+SELECT * FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at, id;
+
+-- name: CountActiveMutedWords :one
+SELECT COUNT(*) FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: DeleteMutedWord :execrows
+DELETE FROM muted_words
+WHERE id = $1 AND user_id = $2;
+
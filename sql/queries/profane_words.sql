@@ -0,0 +1,14 @@
+-- name: UpsertProfaneWord :one
+INSERT INTO profane_words (id, word, whole_word, created_at)
+VALUES (gen_random_uuid(), $1, $2, NOW())
+ON CONFLICT (word) DO UPDATE
+SET whole_word = EXCLUDED.whole_word
+RETURNING *;
+
+-- name: ListProfaneWords :many
+SELECT * FROM profane_words
+ORDER BY word;
+
+-- name: DeleteProfaneWord :execrows
+DELETE FROM profane_words
+WHERE id = $1;
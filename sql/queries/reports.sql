@@ -0,0 +1,32 @@
+-- name: UpsertReport :one
+INSERT INTO reports (id, chirp_id, chirp_author_id, chirp_body, reporter_id, reason, details, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (chirp_id, reporter_id) WHERE status = 'open' DO UPDATE
+SET reason = EXCLUDED.reason, details = EXCLUDED.details
+RETURNING *;
+
+-- name: ListReports :many
+SELECT * FROM reports
+WHERE status = @status
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT @page_size;
+
+-- name: GetReportForUpdate :one
+SELECT * FROM reports
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ResolveReport :one
+UPDATE reports
+SET status = @status, resolved_by = @resolved_by, resolution_note = @resolution_note, resolved_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: ResolveChirpReports :exec
+UPDATE reports
+SET status = @status, resolved_by = @resolved_by, resolution_note = @resolution_note, resolved_at = NOW()
+WHERE chirp_id = @chirp_id AND status = 'open';
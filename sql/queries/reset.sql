@@ -1,2 +1,5 @@
--- name: DeleteUsers :exec
-DELETE FROM users;
+-- name: DeleteOtherUsers :exec
+DELETE FROM users WHERE id <> $1;
+
+-- name: DeleteUserChirps :exec
+DELETE FROM chirps WHERE user_id = $1;
@@ -0,0 +1,25 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, ip, user_agent, created_at, last_used_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW(), NOW())
+RETURNING *;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET ip = $1, user_agent = $2, last_used_at = NOW()
+WHERE id = $3;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE user_id = $1
+AND EXISTS (
+    SELECT 1 FROM refresh_tokens
+    WHERE refresh_tokens.family_id = sessions.id
+    AND refresh_tokens.revoked_at IS NULL
+    AND refresh_tokens.expires_at > NOW()
+)
+ORDER BY last_used_at DESC, id DESC;
+
+-- name: RevokeSession :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW();
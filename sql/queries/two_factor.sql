@@ -0,0 +1,56 @@
+-- name: StartTOTPEnrollment :one
+INSERT INTO user_totp (user_id, secret_ciphertext, created_at, updated_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET secret_ciphertext = EXCLUDED.secret_ciphertext,
+    last_used_step = 0,
+    failed_attempts = 0,
+    last_failed_at = NULL,
+    updated_at = NOW()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE user_id = $1;
+
+-- name: GetUserTOTPForUpdate :one
+SELECT * FROM user_totp
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: ConfirmTOTP :exec
+UPDATE user_totp
+SET confirmed_at = NOW(), updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: RecordTOTPSuccess :exec
+UPDATE user_totp
+SET last_used_step = GREATEST(last_used_step, @step::bigint),
+    failed_attempts = 0,
+    last_failed_at = NULL,
+    updated_at = NOW()
+WHERE user_id = @user_id::uuid;
+
+-- name: RecordTOTPFailure :exec
+UPDATE user_totp
+SET failed_attempts = failed_attempts + 1, last_failed_at = NOW(), updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCodes :exec
+INSERT INTO recovery_codes (id, user_id, code_hash, created_at)
+SELECT gen_random_uuid(), @user_id::uuid, code_hash, NOW()
+FROM unnest(@code_hashes::text[]) AS code_hash;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
@@ -0,0 +1,30 @@
+-- name: CreateUserRestriction :one
+INSERT INTO user_restrictions (id, user_id, kind, reason, created_by, expires_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW())
+RETURNING *;
+
+-- name: ListUserRestrictions :many
+SELECT * FROM user_restrictions
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetActiveRestriction :one
+SELECT * FROM user_restrictions
+WHERE user_id = $1
+AND kind = $2
+AND lifted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY expires_at DESC NULLS FIRST
+LIMIT 1;
+
+-- name: LiftUserRestrictions :many
+UPDATE user_restrictions
+SET lifted_at = NOW(), lifted_by = @lifted_by, lift_reason = @lift_reason
+WHERE user_id = @user_id
+AND kind = @kind
+AND lifted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: IsShadowBanned :one
+SELECT is_restricted(@user_id::uuid, 'shadow_ban');
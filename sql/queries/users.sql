@@ -13,13 +13,37 @@ RETURNING *;
 SELECT * FROM users
 WHERE email = $1;
 
--- name: UpdateUser :one
-UPDATE users
-SET email = $1, hashed_password = $2, updated_at = NOW()
-WHERE id = $3
-RETURNING *;
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
 
 -- name: UpdateUserToChirpyRed :exec
 UPDATE users
 SET is_chirpy_red = TRUE
 WHERE id = $1;
+
+-- name: GetUsersByEmails :many
+SELECT * FROM users
+WHERE lower(email) = ANY(@emails::text[]);
+
+-- name: GetUsersByIDs :many
+SELECT * FROM users
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET email = $1, email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $2
+RETURNING *;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE chirp_mentions (
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX chirp_mentions_user_id_created_at_idx ON chirp_mentions (user_id, created_at, chirp_id);
+
+-- Mentions are matched case-insensitively, see GetUsersByEmails.
+CREATE INDEX users_lower_email_idx ON users (lower(email));
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notifications_user_id_created_at_idx ON notifications (user_id, created_at, id);
+
+-- +goose Down
+DROP TABLE notifications;
+DROP TABLE chirp_mentions;
+DROP INDEX users_lower_email_idx;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE notifications
+ADD read_at TIMESTAMP;
+
+CREATE INDEX notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+
+-- +goose Down
+DROP INDEX notifications_unread_idx;
+
+ALTER TABLE notifications
+DROP COLUMN read_at;
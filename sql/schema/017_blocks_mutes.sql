@@ -0,0 +1,49 @@
+-- +goose Up
+CREATE TABLE blocks (
+    blocker_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    blocked_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+CREATE INDEX blocks_blocker_id_created_at_idx ON blocks (blocker_id, created_at);
+
+CREATE TABLE mutes (
+    muter_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    muted_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (muter_id, muted_id),
+    CHECK (muter_id <> muted_id)
+);
+
+CREATE INDEX mutes_muter_id_created_at_idx ON mutes (muter_id, created_at);
+
+-- A block in either direction makes two users invisible to each other.
+-- +goose StatementBegin
+CREATE FUNCTION is_blocked_between(a UUID, b UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocker_id = a AND blocked_id = b)
+        OR (blocker_id = b AND blocked_id = a)
+    )
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- Content is hidden from a viewer when either side blocked the other or the
+-- viewer muted the author. Anonymous viewers and authors see everything.
+-- +goose StatementBegin
+CREATE FUNCTION is_hidden_from(viewer_id UUID, author_id UUID) RETURNS BOOLEAN AS $$
+    SELECT viewer_id IS NOT NULL AND viewer_id <> author_id AND (
+        is_blocked_between(viewer_id, author_id)
+        OR EXISTS (SELECT 1 FROM mutes WHERE muter_id = viewer_id AND muted_id = author_id)
+    )
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION is_hidden_from;
+DROP FUNCTION is_blocked_between;
+DROP TABLE mutes;
+DROP TABLE blocks;
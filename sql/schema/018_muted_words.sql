@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE muted_words (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    phrase TEXT NOT NULL,
+    whole_word BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, phrase)
+);
+
+-- +goose Down
+DROP TABLE muted_words;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE profane_words (
+    id UUID PRIMARY KEY,
+    word TEXT UNIQUE NOT NULL,
+    whole_word BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO profane_words (id, word, created_at)
+VALUES
+    (gen_random_uuid(), 'kerfuffle', NOW()),
+    (gen_random_uuid(), 'sharbert', NOW()),
+    (gen_random_uuid(), 'fornax', NOW());
+
+-- +goose Down
+DROP TABLE profane_words;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Reports keep a copy of the chirp so they can still be reviewed after the
+-- chirp has been removed.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    chirp_author_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    chirp_body TEXT NOT NULL,
+    reporter_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX reports_open_chirp_id_reporter_id_idx ON reports (chirp_id, reporter_id) WHERE status = 'open';
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at, id);
+
+-- +goose Down
+DROP TABLE reports;
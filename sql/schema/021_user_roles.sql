@@ -0,0 +1,9 @@
+-- +goose Up
+-- The first admin has to be promoted by hand:
+-- UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TABLE user_restrictions (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('suspension', 'shadow_ban')),
+    reason TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    lifted_at TIMESTAMP,
+    lifted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    lift_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX user_restrictions_user_id_kind_idx ON user_restrictions (user_id, kind);
+
+-- +goose StatementBegin
+CREATE FUNCTION is_restricted(restricted_id UUID, restriction_kind TEXT) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM user_restrictions
+        WHERE user_id = restricted_id
+        AND kind = restriction_kind
+        AND lifted_at IS NULL
+        AND (expires_at IS NULL OR expires_at > NOW())
+    )
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- Chirps of shadow-banned users are hidden from everyone but themselves, on
+-- top of the per-viewer blocks and mutes.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION is_hidden_from(viewer_id UUID, author_id UUID) RETURNS BOOLEAN AS $$
+    SELECT viewer_id IS DISTINCT FROM author_id AND (
+        is_restricted(author_id, 'shadow_ban')
+        OR (viewer_id IS NOT NULL AND (
+            is_blocked_between(viewer_id, author_id)
+            OR EXISTS (SELECT 1 FROM mutes WHERE muter_id = viewer_id AND muted_id = author_id)
+        ))
+    )
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION is_hidden_from(viewer_id UUID, author_id UUID) RETURNS BOOLEAN AS $$
+    SELECT viewer_id IS NOT NULL AND viewer_id <> author_id AND (
+        is_blocked_between(viewer_id, author_id)
+        OR EXISTS (SELECT 1 FROM mutes WHERE muter_id = viewer_id AND muted_id = author_id)
+    )
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+DROP FUNCTION is_restricted;
+DROP TABLE user_restrictions;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Audit events reference users without foreign keys so they outlive the
+-- users they are about.
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY,
+    action TEXT NOT NULL,
+    actor_id UUID,
+    target_id UUID,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at, id);
+CREATE INDEX audit_events_actor_id_created_at_idx ON audit_events (actor_id, created_at);
+CREATE INDEX audit_events_target_id_created_at_idx ON audit_events (target_id, created_at);
+CREATE INDEX audit_events_action_created_at_idx ON audit_events (action, created_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_audit_event_changes() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION reject_audit_event_changes();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_changes();
+
+-- +goose Down
+DROP TABLE audit_events;
+DROP FUNCTION reject_audit_event_changes;